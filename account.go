@@ -0,0 +1,25 @@
+package g2fa
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// AccountID derives a stable identifier for the account label at issuer,
+// keyed by a deployment-wide salt. It can stand in for the raw label (often
+// an email address) as a storage key or in logs. The issuer goes through
+// NormalizeIssuer and the label is trimmed, so cosmetic differences map to
+// the same ID; the salt must stay the same for IDs to stay the same.
+func AccountID(issuer, label string, salt []byte) (string, error) {
+	issuer, err := NormalizeIssuer(issuer)
+	if err != nil {
+		return "", err
+	}
+	mac := hmac.New(sha256.New, salt)
+	mac.Write([]byte(issuer))
+	mac.Write([]byte{0})
+	mac.Write([]byte(strings.TrimSpace(label)))
+	return hex.EncodeToString(mac.Sum(nil)), nil
+}
@@ -0,0 +1,45 @@
+package g2fa
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+)
+
+func TestAccountID(t *testing.T) {
+	salt := []byte("deployment salt")
+	id, err := AccountID("ACME Corp", "alice@example.com", salt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mac := hmac.New(sha256.New, salt)
+	mac.Write([]byte("ACME Corp\x00alice@example.com"))
+	if want := hex.EncodeToString(mac.Sum(nil)); id != want {
+		t.Errorf("AccountID = %s, want %s", id, want)
+	}
+
+	same, err := AccountID(" ACME  Corp", " alice@example.com\n", salt)
+	if err != nil || same != id {
+		t.Errorf("AccountID with cosmetic differences = %s, %v, want %s", same, err, id)
+	}
+	for _, tt := range []struct {
+		issuer, label string
+		salt          []byte
+	}{
+		{"ACME", "alice@example.com", salt},
+		{"ACME Corp", "bob@example.com", salt},
+		{"ACME Corp", "alice@example.com", []byte("other salt")},
+		// The separator keeps issuer and label boundaries apart.
+		{"ACME Cor", "palice@example.com", salt},
+	} {
+		other, err := AccountID(tt.issuer, tt.label, tt.salt)
+		if err != nil || other == id {
+			t.Errorf("AccountID(%q, %q, %q) = %s, %v, want a different ID", tt.issuer, tt.label, tt.salt, other, err)
+		}
+	}
+
+	if _, err := AccountID("ACME:Corp", "alice", salt); err != ErrInvalidIssuer {
+		t.Errorf("AccountID with colon issuer error = %v, want ErrInvalidIssuer", err)
+	}
+}
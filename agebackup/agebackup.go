@@ -0,0 +1,74 @@
+// Package agebackup exports g2fa keys encrypted with age, so that backups
+// of an OTP database can be handed to offline custodians who each hold
+// their own identity instead of a shared passphrase.
+//
+// It is a separate module so that the age dependency, and the Go version
+// it requires, stay out of the core g2fa package.
+package agebackup
+
+import (
+	"bufio"
+	"errors"
+	"io"
+	"strings"
+
+	"filippo.io/age"
+	"github.com/ghoroubi/g2fa"
+)
+
+// ErrNoKeys is returned by Export when given no keys.
+var ErrNoKeys = errors.New("agebackup: no keys to export")
+
+// Export writes keys to w encrypted with age to every one of recipients.
+// The plaintext is one otpauth:// URI per line, as written by Key.URI; a
+// key that URI rejects fails the export before anything is written.
+func Export(w io.Writer, keys []*g2fa.Key, recipients ...age.Recipient) error {
+	if len(keys) == 0 {
+		return ErrNoKeys
+	}
+	uris := make([]string, len(keys))
+	for i, k := range keys {
+		uri, err := k.URI()
+		if err != nil {
+			return err
+		}
+		uris[i] = uri
+	}
+
+	enc, err := age.Encrypt(w, recipients...)
+	if err != nil {
+		return err
+	}
+	for _, uri := range uris {
+		if _, err := io.WriteString(enc, uri+"\n"); err != nil {
+			return err
+		}
+	}
+	return enc.Close()
+}
+
+// Import decrypts an export written by Export with one of identities and
+// parses the keys it holds.
+func Import(r io.Reader, identities ...age.Identity) ([]*g2fa.Key, error) {
+	dec, err := age.Decrypt(r, identities...)
+	if err != nil {
+		return nil, err
+	}
+	var keys []*g2fa.Key
+	s := bufio.NewScanner(dec)
+	for s.Scan() {
+		line := strings.TrimSpace(s.Text())
+		if line == "" {
+			continue
+		}
+		k, err := g2fa.ParseKey(line)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
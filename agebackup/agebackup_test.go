@@ -0,0 +1,71 @@
+package agebackup
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"filippo.io/age"
+	"github.com/ghoroubi/g2fa"
+)
+
+func TestExport(t *testing.T) {
+	alice, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := []*g2fa.Key{
+		{Type: g2fa.TypeTOTP, Label: "alice@example.com", Issuer: "Example", Secret: "JBSWY3DPEHPK3PXP"},
+		{Type: g2fa.TypeHOTP, Label: "bob", Issuer: "ACME Corp", Secret: "GEZDGNBV", Digits: 8, Counter: 5},
+	}
+
+	var buf bytes.Buffer
+	if err := Export(&buf, keys, alice.Recipient(), bob.Recipient()); err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(buf.Bytes(), []byte("JBSWY3DPEHPK3PXP")) {
+		t.Error("export contains a plaintext secret")
+	}
+	for _, id := range []age.Identity{alice, bob} {
+		got, err := Import(bytes.NewReader(buf.Bytes()), id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(got, keys) {
+			t.Errorf("Import = %+v, want %+v", got, keys)
+		}
+	}
+
+	other, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Import(bytes.NewReader(buf.Bytes()), other); err == nil {
+		t.Error("Import decrypted with an identity that is not a recipient")
+	}
+}
+
+func TestExportInvalid(t *testing.T) {
+	id, err := age.GenerateX25519Identity()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	if err := Export(&buf, nil, id.Recipient()); err != ErrNoKeys {
+		t.Errorf("Export(nil) error = %v, want ErrNoKeys", err)
+	}
+	bad := []*g2fa.Key{{Type: g2fa.TypeTOTP, Label: "alice", Issuer: "A:B", Secret: "AAAA"}}
+	if err := Export(&buf, bad, id.Recipient()); err != g2fa.ErrInvalidIssuer {
+		t.Errorf("Export with colon issuer error = %v, want ErrInvalidIssuer", err)
+	}
+	if buf.Len() != 0 {
+		t.Errorf("failed exports wrote %d bytes", buf.Len())
+	}
+	if err := Export(&buf, []*g2fa.Key{{Type: g2fa.TypeTOTP, Secret: "AAAA"}}); err == nil {
+		t.Error("Export without recipients succeeded")
+	}
+}
@@ -0,0 +1,16 @@
+module github.com/ghoroubi/g2fa/agebackup
+
+go 1.25.0
+
+require (
+	filippo.io/age v1.3.2
+	github.com/ghoroubi/g2fa v0.0.0-00010101000000-000000000000
+)
+
+require (
+	filippo.io/hpke v0.4.0 // indirect
+	golang.org/x/crypto v0.55.0 // indirect
+	golang.org/x/sys v0.47.0 // indirect
+)
+
+replace github.com/ghoroubi/g2fa => ../
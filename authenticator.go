@@ -0,0 +1,76 @@
+package g2fa
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrUnknownAccount is returned by VirtualAuthenticator.Code for accounts
+// that were not scanned.
+var ErrUnknownAccount = errors.New("g2fa: unknown account")
+
+// VirtualAuthenticator simulates a user's authenticator app in end-to-end
+// tests of enrollment and login: it scans key URIs and shows codes for them
+// on a clock that may drift from the server's. It is not safe for
+// concurrent use.
+type VirtualAuthenticator struct {
+	// Drift is added to the time passed to Code, simulating a device clock
+	// that runs ahead (positive) or behind (negative).
+	Drift time.Duration
+
+	keys []*Key
+}
+
+// Scan adds the account described by an otpauth:// URI, as if its QR code
+// had been scanned, and returns the parsed key. Like authenticator apps,
+// scanning an account again with the same issuer and label replaces it.
+func (a *VirtualAuthenticator) Scan(uri string) (*Key, error) {
+	k, err := ParseKey(uri)
+	if err != nil {
+		return nil, err
+	}
+	if i := a.find(k.Issuer, k.Label); i >= 0 {
+		a.keys[i] = k
+	} else {
+		a.keys = append(a.keys, k)
+	}
+	return k, nil
+}
+
+// Keys returns the scanned accounts in the order they were added.
+func (a *VirtualAuthenticator) Keys() []*Key {
+	return append([]*Key(nil), a.keys...)
+}
+
+// Code returns the code the app shows for the account at now plus Drift.
+// For HOTP accounts it returns the code for the current counter and then
+// advances it, as pressing the app's refresh button does.
+func (a *VirtualAuthenticator) Code(issuer, label string, now time.Time) (string, error) {
+	issuer, err := NormalizeIssuer(issuer)
+	if err != nil {
+		return "", err
+	}
+	i := a.find(issuer, label)
+	if i < 0 {
+		return "", ErrUnknownAccount
+	}
+	k := a.keys[i]
+	if k.Type == TypeHOTP {
+		code, err := GenerateHOTP(k.Secret, k.Counter, k.Options()...)
+		if err != nil {
+			return "", err
+		}
+		k.Counter++
+		return code, nil
+	}
+	return GenerateTOTP(k.Secret, now.Add(a.Drift), k.Options()...)
+}
+
+func (a *VirtualAuthenticator) find(issuer, label string) int {
+	for i, k := range a.keys {
+		if k.Issuer == issuer && k.Label == label {
+			return i
+		}
+	}
+	return -1
+}
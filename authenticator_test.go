@@ -0,0 +1,88 @@
+package g2fa
+
+import (
+	"testing"
+	"time"
+)
+
+func TestVirtualAuthenticatorEnrollment(t *testing.T) {
+	secret, err := GenerateSecret(20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uri, err := (&Key{Type: TypeTOTP, Label: "alice@example.com", Issuer: "Example", Secret: secret}).URI()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var phone VirtualAuthenticator
+	if _, err := phone.Scan(uri); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1_700_000_020, 0)
+
+	tests := []struct {
+		drift time.Duration
+		ok    bool
+	}{
+		{0, true},
+		{25 * time.Second, true},
+		{-35 * time.Second, true},
+		{75 * time.Second, false},
+		{-65 * time.Second, false},
+	}
+	for _, tt := range tests {
+		phone.Drift = tt.drift
+		code, err := phone.Code("Example", "alice@example.com", now)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok, err := ValidateTOTP(secret, code, now); err != nil || ok != tt.ok {
+			t.Errorf("drift %v: ValidateTOTP = %v, %v, want %v", tt.drift, ok, err, tt.ok)
+		}
+	}
+}
+
+func TestVirtualAuthenticatorHOTP(t *testing.T) {
+	var phone VirtualAuthenticator
+	if _, err := phone.Scan("otpauth://hotp/Example:bob?secret=" + rfcSecretSHA1 + "&counter=0"); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"755224", "287082", "359152"} {
+		code, err := phone.Code(" Example", "bob", time.Time{})
+		if err != nil || code != want {
+			t.Errorf("Code = %q, %v, want %q", code, err, want)
+		}
+	}
+	if k := phone.Keys()[0]; k.Counter != 3 {
+		t.Errorf("counter = %d, want 3", k.Counter)
+	}
+}
+
+func TestVirtualAuthenticatorScan(t *testing.T) {
+	var phone VirtualAuthenticator
+	if _, err := phone.Scan("otpauth://totp/Example:alice?secret=AAAA"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := phone.Scan("otpauth://totp/Example:alice?secret=BBBB&digits=8"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := phone.Scan("otpauth://totp/Other:alice?secret=CCCC"); err != nil {
+		t.Fatal(err)
+	}
+	keys := phone.Keys()
+	if len(keys) != 2 || keys[0].Secret != "BBBB" || keys[1].Issuer != "Other" {
+		t.Fatalf("Keys() = %+v, want the rescanned Example key and Other", keys)
+	}
+	code, err := phone.Code("Example", "alice", time.Unix(59, 0))
+	if err != nil || len(code) != 8 {
+		t.Errorf("Code = %q, %v, want 8 digits", code, err)
+	}
+
+	if _, err := phone.Scan("https://example.com"); err != ErrInvalidKeyURI {
+		t.Errorf("Scan error = %v, want ErrInvalidKeyURI", err)
+	}
+	if _, err := phone.Code("Example", "bob", time.Now()); err != ErrUnknownAccount {
+		t.Errorf("Code for unknown account error = %v, want ErrUnknownAccount", err)
+	}
+}
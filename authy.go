@@ -0,0 +1,109 @@
+package g2fa
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// Authy-managed accounts ("Authy apps" such as Twitch) use 7 digit codes
+// with a 10 second period unless the export says otherwise.
+const (
+	authyAppDigits = 7
+	authyAppPeriod = 10
+)
+
+// ErrInvalidAuthyEntry is returned by ImportAuthyJSON for entries that carry
+// neither a usable URI nor a secret.
+var ErrInvalidAuthyEntry = errors.New("g2fa: invalid Authy export entry")
+
+// authyEntry is one account in an Authy export produced by community tools.
+// Regular TOTP accounts carry a base32 secret, Authy apps a hex seed; some
+// tools also include a ready otpauth URI.
+type authyEntry struct {
+	Name          string `json:"name"`
+	Issuer        string `json:"issuer"`
+	Secret        string `json:"secret"`
+	DecryptedSeed string `json:"decryptedSeed"`
+	Seed          string `json:"seed"`
+	Digits        int    `json:"digits"`
+	Period        int    `json:"period"`
+	URI           string `json:"uri"`
+}
+
+// ImportAuthyJSON converts an Authy export, a JSON array of accounts as
+// written by the common community export scripts, into keys. Entries with an
+// otpauth URI are parsed with ParseKey and keep the URI's type, which may be
+// HOTP; all other entries become TOTP keys. Entries with a hex seed are Authy
+// apps and default to 7 digits and a 10 second period; decryptedSeed is used
+// when present, seed otherwise. Entries with a base32 secret keep the
+// authenticator defaults unless digits or period are given.
+func ImportAuthyJSON(data []byte) ([]*Key, error) {
+	var entries []authyEntry
+	if err := json.Unmarshal(data, &entries); err != nil {
+		return nil, err
+	}
+	keys := make([]*Key, 0, len(entries))
+	for _, e := range entries {
+		k, err := e.key()
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, nil
+}
+
+func (e *authyEntry) key() (*Key, error) {
+	if e.URI != "" {
+		return ParseKey(e.URI)
+	}
+
+	k := &Key{Type: TypeTOTP, Digits: e.Digits, Period: e.Period}
+	seed := e.DecryptedSeed
+	if seed == "" {
+		seed = e.Seed
+	}
+	switch {
+	case seed != "":
+		raw, err := hex.DecodeString(strings.TrimSpace(seed))
+		if err != nil || len(raw) == 0 {
+			return nil, ErrInvalidAuthyEntry
+		}
+		k.Secret = encodeSecret(raw)
+		wipe(raw)
+		if k.Digits == 0 {
+			k.Digits = authyAppDigits
+		}
+		if k.Period == 0 {
+			k.Period = authyAppPeriod
+		}
+	case e.Secret != "":
+		raw, err := decodeSecret(e.Secret)
+		if err != nil || len(raw) == 0 {
+			return nil, ErrInvalidAuthyEntry
+		}
+		k.Secret = encodeSecret(raw)
+		wipe(raw)
+	default:
+		return nil, ErrInvalidAuthyEntry
+	}
+
+	// Names are often "Issuer: account", as in key URI labels.
+	name, issuer := e.Name, e.Issuer
+	if i := strings.Index(name, ":"); i >= 0 {
+		if issuer == "" {
+			issuer = name[:i]
+		}
+		if strings.TrimSpace(name[:i]) == strings.TrimSpace(issuer) {
+			name = name[i+1:]
+		}
+	}
+	var err error
+	if k.Issuer, err = NormalizeIssuer(issuer); err != nil {
+		return nil, err
+	}
+	k.Label = strings.TrimSpace(name)
+	return k, nil
+}
@@ -0,0 +1,57 @@
+package g2fa
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestImportAuthyJSON(t *testing.T) {
+	data := []byte(`[
+		{"name": "Example: alice", "secret": "jbsw y3dp ehpk 3pxp"},
+		{"name": "Twitch", "issuer": "Twitch", "decryptedSeed": "48656c6c6f21deadbeef"},
+		{"name": "bob", "issuer": "ACME  Corp", "decryptedSeed": "48656c6c6f21deadbeef", "seed": "00ff", "digits": 6},
+		{"name": "carol", "seed": "48656c6c6f21deadbeef", "period": 30},
+		{"uri": "otpauth://hotp/Example:dave?secret=JBSWY3DPEHPK3PXP&issuer=Example&counter=3"}
+	]`)
+	keys, err := ImportAuthyJSON(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*Key{
+		{Type: TypeTOTP, Label: "alice", Issuer: "Example", Secret: "JBSWY3DPEHPK3PXP"},
+		{Type: TypeTOTP, Label: "Twitch", Issuer: "Twitch", Secret: "JBSWY3DPEHPK3PXP", Digits: 7, Period: 10},
+		{Type: TypeTOTP, Label: "bob", Issuer: "ACME Corp", Secret: "JBSWY3DPEHPK3PXP", Digits: 6, Period: 10},
+		{Type: TypeTOTP, Label: "carol", Secret: "JBSWY3DPEHPK3PXP", Digits: 7, Period: 30},
+		{Type: TypeHOTP, Label: "dave", Issuer: "Example", Secret: "JBSWY3DPEHPK3PXP", Counter: 3},
+	}
+	if len(keys) != len(want) {
+		t.Fatalf("got %d keys, want %d", len(keys), len(want))
+	}
+	for i := range want {
+		if !reflect.DeepEqual(keys[i], want[i]) {
+			t.Errorf("key %d = %+v, want %+v", i, keys[i], want[i])
+		}
+	}
+}
+
+func TestImportAuthyJSONInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want error
+	}{
+		{"no secret", `[{"name": "alice"}]`, ErrInvalidAuthyEntry},
+		{"bad seed", `[{"name": "alice", "seed": "xyz"}]`, ErrInvalidAuthyEntry},
+		{"bad secret", `[{"name": "alice", "secret": "!!!!"}]`, ErrInvalidAuthyEntry},
+		{"colon issuer", `[{"name": "alice", "issuer": "A:B", "secret": "AAAA"}]`, ErrInvalidIssuer},
+		{"bad uri", `[{"uri": "https://example.com"}]`, ErrInvalidKeyURI},
+	}
+	for _, tt := range tests {
+		if _, err := ImportAuthyJSON([]byte(tt.data)); err != tt.want {
+			t.Errorf("%s: error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+	if _, err := ImportAuthyJSON([]byte(`{}`)); err == nil {
+		t.Error("ImportAuthyJSON accepted a non-array document")
+	}
+}
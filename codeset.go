@@ -0,0 +1,170 @@
+package g2fa
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"time"
+)
+
+// MaxCodeSetSteps is the largest number of time steps a sealed code set may
+// hold, window included: a little over a year of 30 second steps.
+const MaxCodeSetSteps = 1 << 20
+
+var (
+	// ErrCodeSetSize is returned by PregenerateTOTP when the time range is
+	// empty or spans more than MaxCodeSetSteps steps.
+	ErrCodeSetSize = errors.New("g2fa: code set must cover between 1 and 1048576 steps")
+	// ErrInvalidCodeSet is returned by OpenCodeSet when the sealed set is
+	// malformed or the key is wrong.
+	ErrInvalidCodeSet = errors.New("g2fa: invalid or tampered code set")
+	// ErrCodeSetRange is returned by CodeSet.Validate for times outside the
+	// range the set was generated for.
+	ErrCodeSetRange = errors.New("g2fa: time outside code set range")
+)
+
+// codeSetAD is authenticated along with every sealed code set, binding it to
+// this format.
+var codeSetAD = []byte("g2fa code set v1")
+
+const codeSetHeaderSize = 1 + 1 + 1 + 4 + 8 + 8 + 8
+
+// CodeSet holds the TOTP codes of a time range, so that codes can be
+// validated without the secret, such as on an air-gapped kiosk.
+type CodeSet struct {
+	digits   int
+	window   int
+	period   time.Duration
+	from, to uint64 // time steps Validate accepts
+	first    uint64 // time step of codes[:digits]
+	codes    []byte // ASCII digits, one code per step
+}
+
+// PregenerateTOTP computes the TOTP codes for secret from time from to time
+// to, plus the validation window on both sides, and seals them with
+// AES-GCM under key, which must be 16, 24 or 32 bytes. The result can be
+// carried to a validating device holding key and opened there with
+// OpenCodeSet; the secret itself never leaves the caller. Options are those
+// of ValidateTOTP and apply to the codes and to CodeSet.Validate.
+func PregenerateTOTP(secret string, from, to time.Time, key []byte, opts ...Option) ([]byte, error) {
+	o := otpOptions{window: 1}
+	p, err := newOTPParams(secret, &o, opts)
+	if err != nil {
+		return nil, err
+	}
+	fromStep, err := timeStep(from, o.period)
+	if err != nil {
+		return nil, err
+	}
+	toStep, err := timeStep(to, o.period)
+	if err != nil {
+		return nil, err
+	}
+	if toStep < fromStep || toStep-fromStep >= MaxCodeSetSteps {
+		return nil, ErrCodeSetSize
+	}
+	first := fromStep - min(fromStep, uint64(o.window))
+	count := toStep + uint64(o.window) - first + 1
+	if count > MaxCodeSetSteps {
+		return nil, ErrCodeSetSize
+	}
+	aead, err := newCodeSetAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := make([]byte, codeSetHeaderSize, codeSetHeaderSize+int(count)*o.digits)
+	payload[0] = 1
+	payload[1] = byte(o.digits)
+	payload[2] = byte(o.window)
+	binary.BigEndian.PutUint32(payload[3:], uint32(o.period/time.Second))
+	binary.BigEndian.PutUint64(payload[7:], fromStep)
+	binary.BigEndian.PutUint64(payload[15:], toStep)
+	binary.BigEndian.PutUint64(payload[23:], first)
+	var code [8]byte
+	for i := uint64(0); i < count; i++ {
+		p.truncate(first+i, code[:o.digits])
+		payload = append(payload, code[:o.digits]...)
+	}
+
+	nonce := make([]byte, aead.NonceSize(), aead.NonceSize()+len(payload)+aead.Overhead())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	sealed := aead.Seal(nonce, nonce, payload, codeSetAD)
+	wipe(payload)
+	return sealed, nil
+}
+
+// OpenCodeSet decrypts a code set sealed by PregenerateTOTP.
+func OpenCodeSet(sealed, key []byte) (*CodeSet, error) {
+	aead, err := newCodeSetAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < aead.NonceSize() {
+		return nil, ErrInvalidCodeSet
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	payload, err := aead.Open(nil, nonce, ciphertext, codeSetAD)
+	if err != nil || len(payload) < codeSetHeaderSize || payload[0] != 1 {
+		return nil, ErrInvalidCodeSet
+	}
+
+	c := &CodeSet{
+		digits: int(payload[1]),
+		window: int(payload[2]),
+		period: time.Duration(binary.BigEndian.Uint32(payload[3:])) * time.Second,
+		from:   binary.BigEndian.Uint64(payload[7:]),
+		to:     binary.BigEndian.Uint64(payload[15:]),
+		first:  binary.BigEndian.Uint64(payload[23:]),
+		codes:  payload[codeSetHeaderSize:],
+	}
+	if c.digits < 6 || c.digits > 8 || c.period == 0 || len(c.codes)%c.digits != 0 || c.from < c.first || c.to < c.from ||
+		c.to-c.first+uint64(c.window) >= uint64(len(c.codes)/c.digits) {
+		return nil, ErrInvalidCodeSet
+	}
+	return c, nil
+}
+
+// Validate checks code at time t like ValidateTOTP does with the secret,
+// returning the matched time step. Times outside the range the set was
+// generated for return ErrCodeSetRange.
+func (c *CodeSet) Validate(code string, t time.Time) (step uint64, ok bool, err error) {
+	current, err := timeStep(t, c.period)
+	if err != nil {
+		return 0, false, err
+	}
+	if current < c.from || current > c.to {
+		return 0, false, ErrCodeSetRange
+	}
+	if code, err = (&otpParams{digits: c.digits}).parseCode(code); err != nil {
+		return 0, false, err
+	}
+
+	for s := current - min(current, uint64(c.window)); s <= current+uint64(c.window); s++ {
+		i := int(s-c.first) * c.digits
+		if subtle.ConstantTimeCompare([]byte(code), c.codes[i:i+c.digits]) == 1 {
+			return s, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// Range returns the start of the first and the end of the last time step
+// the set covers.
+func (c *CodeSet) Range() (from, to time.Time) {
+	period := int64(c.period / time.Second)
+	return time.Unix(int64(c.from)*period, 0), time.Unix(int64(c.to+1)*period, 0)
+}
+
+func newCodeSetAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,101 @@
+package g2fa
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+)
+
+var codeSetKey = bytes.Repeat([]byte{0x42}, 32)
+
+func TestCodeSet(t *testing.T) {
+	from, to := time.Unix(1111111080, 0), time.Unix(1111111140, 0)
+	sealed, err := PregenerateTOTP(rfcSecretSHA1, from, to, codeSetKey, WithDigits(8))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(sealed, []byte("07081804")) {
+		t.Error("sealed code set contains a plaintext code")
+	}
+	set, err := OpenCodeSet(sealed, codeSetKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f, e := set.Range(); !f.Equal(from) || !e.Equal(to.Add(30*time.Second)) {
+		t.Errorf("Range() = %v, %v", f, e)
+	}
+
+	// The set agrees with ValidateTOTP over its whole range, window included.
+	at := time.Unix(1111111111, 0)
+	for s := int64(37037034); s <= 37037041; s++ {
+		code, _ := GenerateTOTP(rfcSecretSHA1, time.Unix(s*30, 0), WithDigits(8))
+		wantStep, wantOK, _ := ValidateTOTP(rfcSecretSHA1, code, at, WithDigits(8))
+		step, ok, err := set.Validate(code, at)
+		if err != nil || ok != wantOK || step != wantStep {
+			t.Errorf("Validate(code of step %d) = %d, %v, %v, want %d, %v", s, step, ok, err, wantStep, wantOK)
+		}
+	}
+	if step, ok, err := set.Validate("0708 1804", time.Unix(1111111109, 0)); err != nil || !ok || step != 37037036 {
+		t.Errorf("Validate(RFC code) = %d, %v, %v", step, ok, err)
+	}
+
+	for _, tt := range []time.Time{from.Add(-time.Second), to.Add(30 * time.Second)} {
+		if _, _, err := set.Validate("07081804", tt); err != ErrCodeSetRange {
+			t.Errorf("Validate at %v error = %v, want ErrCodeSetRange", tt, err)
+		}
+	}
+	var length *ErrBadLength
+	if _, _, err := set.Validate("123456", at); !errors.As(err, &length) {
+		t.Errorf("Validate(short code) error = %v, want *ErrBadLength", err)
+	}
+}
+
+func TestCodeSetEpoch(t *testing.T) {
+	sealed, err := PregenerateTOTP(rfcSecretSHA1, time.Unix(0, 0), time.Unix(59, 0), codeSetKey, WithWindow(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	set, err := OpenCodeSet(sealed, codeSetKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code, _ := GenerateTOTP(rfcSecretSHA1, time.Unix(90, 0))
+	if step, ok, err := set.Validate(code, time.Unix(0, 0)); err != nil || ok {
+		t.Errorf("Validate beyond window = %d, %v, %v", step, ok, err)
+	}
+	if step, ok, err := set.Validate(code, time.Unix(31, 0)); err != nil || !ok || step != 3 {
+		t.Errorf("Validate within window = %d, %v, %v, want step 3", step, ok, err)
+	}
+}
+
+func TestCodeSetInvalid(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	if _, err := PregenerateTOTP(rfcSecretSHA1, now, now.Add(-time.Minute), codeSetKey); err != ErrCodeSetSize {
+		t.Errorf("reversed range error = %v, want ErrCodeSetSize", err)
+	}
+	if _, err := PregenerateTOTP(rfcSecretSHA1, now, now.Add(MaxCodeSetSteps*30*time.Second), codeSetKey); err != ErrCodeSetSize {
+		t.Errorf("oversized range error = %v, want ErrCodeSetSize", err)
+	}
+	if _, err := PregenerateTOTP(rfcSecretSHA1, now, now, []byte("short")); err == nil {
+		t.Error("PregenerateTOTP accepted a 5 byte key")
+	}
+	if _, err := PregenerateTOTP(rfcSecretSHA1, now, now, codeSetKey, WithDigits(9)); err != ErrInvalidDigits {
+		t.Errorf("invalid digits error = %v, want ErrInvalidDigits", err)
+	}
+
+	sealed, err := PregenerateTOTP(rfcSecretSHA1, now, now.Add(time.Hour), codeSetKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenCodeSet(sealed, bytes.Repeat([]byte{0x43}, 32)); err != ErrInvalidCodeSet {
+		t.Errorf("wrong key error = %v, want ErrInvalidCodeSet", err)
+	}
+	sealed[len(sealed)/2] ^= 1
+	if _, err := OpenCodeSet(sealed, codeSetKey); err != ErrInvalidCodeSet {
+		t.Errorf("tampered set error = %v, want ErrInvalidCodeSet", err)
+	}
+	if _, err := OpenCodeSet(sealed[:4], codeSetKey); err != ErrInvalidCodeSet {
+		t.Errorf("truncated set error = %v, want ErrInvalidCodeSet", err)
+	}
+}
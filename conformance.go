@@ -0,0 +1,81 @@
+package g2fa
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os/exec"
+	"strings"
+)
+
+// ConformanceFunc asks the implementation under test for the code of v.
+type ConformanceFunc func(v Vector) (string, error)
+
+// Mismatch is a vector for which the implementation under test returned a
+// different code, or failed with Err.
+type Mismatch struct {
+	Vector Vector
+	Got    string
+	Err    error
+}
+
+// RunConformance checks the implementation behind f against vectors, as
+// produced by GenerateVectors, and returns the vectors it got wrong. Codes
+// are compared after trimming surrounding whitespace.
+func RunConformance(vectors []Vector, f ConformanceFunc) []Mismatch {
+	var mismatches []Mismatch
+	for _, v := range vectors {
+		got, err := f(v)
+		got = strings.TrimSpace(got)
+		if err != nil || got != v.Code {
+			mismatches = append(mismatches, Mismatch{Vector: v, Got: got, Err: err})
+		}
+	}
+	return mismatches
+}
+
+// CommandConformance returns a ConformanceFunc that runs the named command
+// once per vector, writing the vector as JSON to its standard input and
+// reading the code from its standard output.
+func CommandConformance(name string, args ...string) ConformanceFunc {
+	return func(v Vector) (string, error) {
+		in, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		cmd := exec.Command(name, args...)
+		cmd.Stdin = bytes.NewReader(in)
+		out, err := cmd.Output()
+		return string(out), err
+	}
+}
+
+// HTTPConformance returns a ConformanceFunc that POSTs each vector as JSON
+// to url and reads the code from the response body, which must come with a
+// 200 status. A nil client means http.DefaultClient.
+func HTTPConformance(client *http.Client, url string) ConformanceFunc {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	return func(v Vector) (string, error) {
+		in, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		resp, err := client.Post(url, "application/json", bytes.NewReader(in))
+		if err != nil {
+			return "", err
+		}
+		defer resp.Body.Close()
+		out, err := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		if err != nil {
+			return "", err
+		}
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("g2fa: conformance endpoint returned %s", resp.Status)
+		}
+		return string(out), nil
+	}
+}
@@ -0,0 +1,98 @@
+package g2fa
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+func conformanceVectors(t *testing.T) []Vector {
+	t.Helper()
+	vectors, err := GenerateVectors(rfcSecretSHA1, VectorMatrix{
+		Algorithms: []string{"SHA1", "SHA256"},
+		Digits:     []int{6, 8},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return vectors
+}
+
+// referenceCode is a stand-in implementation that agrees with this package
+// except for SHA256, which it ignores.
+func referenceCode(v Vector) (string, error) {
+	return GenerateTOTP(v.Secret, time.Unix(v.Time, 0), WithDigits(v.Digits), WithPeriod(time.Duration(v.Period)*time.Second))
+}
+
+func TestRunConformance(t *testing.T) {
+	vectors := conformanceVectors(t)
+	mismatches := RunConformance(vectors, func(v Vector) (string, error) {
+		code, err := referenceCode(v)
+		return " " + code + "\n", err
+	})
+	if len(mismatches) != len(vectors)/2 {
+		t.Fatalf("got %d mismatches, want %d", len(mismatches), len(vectors)/2)
+	}
+	for _, m := range mismatches {
+		if m.Vector.Algorithm != "SHA256" || m.Err != nil || m.Got == m.Vector.Code {
+			t.Errorf("unexpected mismatch %+v", m)
+		}
+	}
+}
+
+func TestHTTPConformance(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var v Vector
+		if err := json.NewDecoder(r.Body).Decode(&v); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		code, err := GenerateTOTP(v.Secret, time.Unix(v.Time, 0), WithAlgorithm(v.Algorithm),
+			WithDigits(v.Digits), WithPeriod(time.Duration(v.Period)*time.Second))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.Write([]byte(code))
+	}))
+	defer srv.Close()
+
+	if mismatches := RunConformance(conformanceVectors(t), HTTPConformance(nil, srv.URL)); len(mismatches) != 0 {
+		t.Errorf("got mismatches %+v", mismatches)
+	}
+
+	broken := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "94287082", http.StatusInternalServerError)
+	}))
+	defer broken.Close()
+	vectors := conformanceVectors(t)
+	mismatches := RunConformance(vectors, HTTPConformance(broken.Client(), broken.URL))
+	if len(mismatches) != len(vectors) || mismatches[0].Err == nil {
+		t.Errorf("broken endpoint: %d mismatches, first %+v, want all with errors", len(mismatches), mismatches[0])
+	}
+}
+
+func TestCommandConformance(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("no sh in PATH")
+	}
+	vectors, err := GenerateVectors(rfcSecretSHA1, VectorMatrix{Digits: []int{8}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A command that only knows the first RFC 6238 code, and checks it got
+	// the vector on standard input.
+	f := CommandConformance("sh", "-c", `grep -q '"time":59,' && echo 94287082`)
+	mismatches := RunConformance(vectors, f)
+	if len(mismatches) != len(vectors)-1 {
+		t.Fatalf("got %d mismatches, want %d", len(mismatches), len(vectors)-1)
+	}
+	for _, m := range mismatches {
+		if m.Vector.Time == 59 || m.Err == nil {
+			t.Errorf("unexpected mismatch %+v", m)
+		}
+	}
+}
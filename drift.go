@@ -0,0 +1,88 @@
+package g2fa
+
+import "time"
+
+// driftBaseStep is the server time step SimulateDrift starts from, far
+// enough from the epoch for any drift that can still be accepted.
+const driftBaseStep = 1 << 24
+
+// DriftResult reports how codes from a client whose clock is Drift ahead of
+// the server (behind when negative) fare against ValidateTOTP. The server
+// is placed at each whole second of a time step in turn, as the outcome
+// depends on where in the step the code is checked.
+type DriftResult struct {
+	Drift    time.Duration `json:"drift"`
+	Accepted int           `json:"accepted"` // seconds of the step at which the code is accepted
+	Phases   int           `json:"phases"`   // seconds tried, the period
+}
+
+// Always reports whether the code was accepted at every second of the step.
+func (r DriftResult) Always() bool { return r.Accepted == r.Phases }
+
+// SimulateDrift validates codes from clients with each of the given clock
+// drifts against a server configured with opts, using a random secret, and
+// reports the acceptance for each drift. It lets operators pick a window
+// from measured client drift rather than by guessing.
+func SimulateDrift(drifts []time.Duration, opts ...Option) ([]DriftResult, error) {
+	secret, err := GenerateSecret(20)
+	if err != nil {
+		return nil, err
+	}
+	o := otpOptions{period: DefaultPeriod}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	if _, err := timeStep(time.Unix(0, 0), o.period); err != nil {
+		return nil, err
+	}
+	phases := int(o.period / time.Second)
+	base := time.Unix(driftBaseStep*int64(phases), 0)
+
+	results := make([]DriftResult, 0, len(drifts))
+	for _, drift := range drifts {
+		r := DriftResult{Drift: drift, Phases: phases}
+		for i := 0; i < phases; i++ {
+			server := base.Add(time.Duration(i) * time.Second)
+			client := server.Add(drift)
+			code, err := GenerateTOTP(secret, client, opts...)
+			if err != nil {
+				return nil, err
+			}
+			step, ok, err := ValidateTOTP(secret, code, server, opts...)
+			if err != nil {
+				return nil, err
+			}
+			// A match on another step is a chance collision, not acceptance.
+			if want, _ := timeStep(client, o.period); ok && step == want {
+				r.Accepted++
+			}
+		}
+		results = append(results, r)
+	}
+	return results, nil
+}
+
+// DriftEnvelope returns the largest whole-second drifts behind and ahead of
+// the server for which SimulateDrift accepts codes at every second of the
+// step, and every smaller drift is accepted too.
+func DriftEnvelope(opts ...Option) (behind, ahead time.Duration, err error) {
+	for _, sign := range []time.Duration{-1, 1} {
+		var d time.Duration
+		for {
+			r, err := SimulateDrift([]time.Duration{sign * (d + time.Second)}, opts...)
+			if err != nil {
+				return 0, 0, err
+			}
+			if !r[0].Always() {
+				break
+			}
+			d += time.Second
+		}
+		if sign < 0 {
+			behind = d
+		} else {
+			ahead = d
+		}
+	}
+	return behind, ahead, nil
+}
@@ -0,0 +1,50 @@
+package g2fa
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSimulateDrift(t *testing.T) {
+	drifts := []time.Duration{0, 30 * time.Second, 45 * time.Second, -45 * time.Second, 60 * time.Second, -61 * time.Second}
+	results, err := SimulateDrift(drifts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []int{30, 30, 15, 15, 0, 0}
+	for i, r := range results {
+		if r.Drift != drifts[i] || r.Phases != 30 || r.Accepted != want[i] {
+			t.Errorf("drift %v: %+v, want %d of 30 accepted", drifts[i], r, want[i])
+		}
+	}
+	if !results[1].Always() || results[2].Always() {
+		t.Errorf("Always() = %v, %v, want true, false", results[1].Always(), results[2].Always())
+	}
+}
+
+func TestDriftEnvelope(t *testing.T) {
+	tests := []struct {
+		opts          []Option
+		behind, ahead time.Duration
+	}{
+		{nil, 30 * time.Second, 30 * time.Second},
+		{[]Option{WithWindow(0)}, 0, 0},
+		{[]Option{WithWindow(2)}, 60 * time.Second, 60 * time.Second},
+		{[]Option{WithPeriod(60 * time.Second), WithDigits(8)}, 60 * time.Second, 60 * time.Second},
+	}
+	for _, tt := range tests {
+		behind, ahead, err := DriftEnvelope(tt.opts...)
+		if err != nil || behind != tt.behind || ahead != tt.ahead {
+			t.Errorf("DriftEnvelope = %v, %v, %v, want %v, %v", behind, ahead, err, tt.behind, tt.ahead)
+		}
+	}
+}
+
+func TestSimulateDriftInvalid(t *testing.T) {
+	if _, err := SimulateDrift([]time.Duration{0}, WithPeriod(0)); err != ErrInvalidPeriod {
+		t.Errorf("SimulateDrift error = %v, want ErrInvalidPeriod", err)
+	}
+	if _, _, err := DriftEnvelope(WithWindow(-1)); err != ErrInvalidWindow {
+		t.Errorf("DriftEnvelope error = %v, want ErrInvalidWindow", err)
+	}
+}
@@ -0,0 +1,3 @@
+module github.com/ghoroubi/g2fa
+
+go 1.21
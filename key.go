@@ -0,0 +1,335 @@
+package g2fa
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Key types as used in the host part of otpauth:// URIs.
+const (
+	TypeTOTP = "totp"
+	TypeHOTP = "hotp"
+)
+
+var (
+	// ErrInvalidKeyURI is returned by ParseKey when the URI is not a valid
+	// otpauth:// key URI.
+	ErrInvalidKeyURI = errors.New("g2fa: invalid otpauth key URI")
+	// ErrInvalidIssuer is returned for issuers containing a colon, which the
+	// key URI format reserves as the issuer/account separator.
+	ErrInvalidIssuer = errors.New("g2fa: issuer must not contain a colon")
+)
+
+// Key is an account as described by an otpauth:// key URI, the format read
+// by Google Authenticator and compatible apps. For keys returned by ParseKey,
+// parsing the output of String or URI yields an equal Key, including
+// parameters this package does not interpret and repeated ones. Keys built
+// by hand round-trip too once their issuer is normalized (see
+// NormalizeIssuer), except for a Label containing a colon on a Key without
+// issuer, which reads back as an issuer prefix.
+//
+// Zero values mean the parameter is absent and apps apply their defaults:
+// SHA1, 6 digits and a 30 second period.
+type Key struct {
+	Type      string // TypeTOTP or TypeHOTP
+	Label     string // account name, without the issuer prefix
+	Issuer    string
+	Secret    string // base32 encoded
+	Algorithm string
+	Digits    int
+	Period    int    // seconds, TOTP only
+	Counter   uint64 // HOTP, written for TOTP too when non-zero
+
+	// LabelIssuer is the issuer prefix of the label when it differs from
+	// the issuer parameter. It is empty in the common case where both agree.
+	LabelIssuer string
+
+	// Params holds any other query parameters of the URI, as well as the
+	// second and later values of repeated known parameters.
+	Params url.Values
+}
+
+// ParseKey parses an otpauth:// URI such as
+//
+//	otpauth://totp/Example:alice@example.com?secret=JBSWY3DPEHPK3PXP&issuer=Example
+//
+// The issuer is taken from the issuer parameter, falling back to the label
+// prefix when the parameter is missing, and goes through NormalizeIssuer:
+// "ACME%20%20Corp" reads as "ACME Corp".
+func ParseKey(uri string) (*Key, error) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "otpauth" {
+		return nil, ErrInvalidKeyURI
+	}
+	k := &Key{Type: strings.ToLower(u.Host)}
+	if k.Type != TypeTOTP && k.Type != TypeHOTP {
+		return nil, ErrInvalidKeyURI
+	}
+
+	label := strings.TrimPrefix(u.Path, "/")
+	var prefix string
+	if i := strings.Index(label, ":"); i >= 0 {
+		prefix, label = label[:i], strings.TrimLeft(label[i+1:], " ")
+	}
+	k.Label = label
+
+	q := u.Query()
+	k.Secret = q.Get("secret")
+	if k.Secret == "" {
+		return nil, ErrInvalidKeyURI
+	}
+	issuer := q.Get("issuer")
+	if issuer == "" {
+		issuer = prefix
+	}
+	if k.Issuer, err = NormalizeIssuer(issuer); err != nil {
+		return nil, err
+	}
+	if prefix != k.Issuer {
+		k.LabelIssuer = prefix
+	}
+	k.Algorithm = q.Get("algorithm")
+	if v := q.Get("digits"); v != "" {
+		if k.Digits, err = strconv.Atoi(v); err != nil || k.Digits <= 0 {
+			return nil, ErrInvalidKeyURI
+		}
+	}
+	if v := q.Get("period"); v != "" {
+		if k.Period, err = strconv.Atoi(v); err != nil || k.Period <= 0 {
+			return nil, ErrInvalidKeyURI
+		}
+	}
+	if v := q.Get("counter"); v != "" {
+		if k.Counter, err = strconv.ParseUint(v, 10, 64); err != nil {
+			return nil, ErrInvalidKeyURI
+		}
+	} else if k.Type == TypeHOTP {
+		return nil, ErrInvalidKeyURI
+	}
+
+	for _, name := range []string{"secret", "issuer", "algorithm", "digits", "period", "counter"} {
+		if vs := q[name]; len(vs) > 1 {
+			q[name] = vs[1:]
+		} else {
+			q.Del(name)
+		}
+	}
+	if len(q) > 0 {
+		k.Params = q
+	}
+	return k, nil
+}
+
+// Options returns the options matching the key's algorithm, digits and
+// period, for use with the Generate and Validate functions. Parameters the
+// key leaves unset are omitted so the defaults apply.
+func (k *Key) Options() []Option {
+	var opts []Option
+	if k.Algorithm != "" {
+		opts = append(opts, WithAlgorithm(k.Algorithm))
+	}
+	if k.Digits != 0 {
+		opts = append(opts, WithDigits(k.Digits))
+	}
+	if k.Period != 0 {
+		opts = append(opts, WithPeriod(time.Duration(k.Period)*time.Second))
+	}
+	return opts
+}
+
+// NormalizeIssuer trims an issuer name and collapses inner runs of
+// whitespace, so that "ACME  Corp " and "ACME Corp" end up as the same
+// account in authenticator apps. Issuers containing a colon are rejected as
+// the key URI format does not allow them.
+func NormalizeIssuer(issuer string) (string, error) {
+	if strings.Contains(issuer, ":") {
+		return "", ErrInvalidIssuer
+	}
+	return strings.Join(strings.Fields(issuer), " "), nil
+}
+
+// CanonicalIssuerDomain normalizes an issuer given as a domain name: it is
+// lowercased and stripped of any scheme, port, path, trailing dot and
+// leading "www." label. Other subdomains are kept, since telling a
+// registrable domain apart from a shared suffix needs the public suffix
+// list. IP addresses, bracketed or not, are returned in their canonical
+// form.
+func CanonicalIssuerDomain(domain string) string {
+	domain = strings.ToLower(strings.TrimSpace(domain))
+	if i := strings.Index(domain, "://"); i >= 0 {
+		domain = domain[i+3:]
+	}
+	if i := strings.IndexAny(domain, "/?#"); i >= 0 {
+		domain = domain[:i]
+	}
+	if host, _, err := net.SplitHostPort(domain); err == nil {
+		domain = host
+	}
+	domain = strings.TrimSuffix(strings.TrimPrefix(domain, "["), "]")
+	if ip := net.ParseIP(domain); ip != nil {
+		return ip.String()
+	}
+	domain = strings.TrimSuffix(domain, ".")
+	return strings.TrimPrefix(domain, "www.")
+}
+
+// URI returns the otpauth:// URI for the key after checking it can be read
+// back: the type must be TypeTOTP or TypeHOTP, the secret set and neither
+// issuer nor label prefix may contain a colon. The issuer is normalized with
+// NormalizeIssuer.
+func (k *Key) URI() (string, error) {
+	if k.Type != TypeTOTP && k.Type != TypeHOTP || k.Secret == "" {
+		return "", ErrInvalidKeyURI
+	}
+	issuer, err := NormalizeIssuer(k.Issuer)
+	if err != nil {
+		return "", err
+	}
+	if strings.Contains(k.LabelIssuer, ":") {
+		return "", ErrInvalidIssuer
+	}
+	return k.format(issuer), nil
+}
+
+// String returns the otpauth:// URI for the key, like URI but without the
+// checks. The issuer's whitespace is normalized, and an issuer containing a
+// colon is left out of the label so that the label stays unambiguous. Use
+// URI to detect such keys.
+func (k *Key) String() string {
+	return k.format(strings.Join(strings.Fields(k.Issuer), " "))
+}
+
+// Enrollment returns what an enrollment page shows for the key: link is
+// the otpauth:// deep link, as returned by URI, for devices that can open
+// it, and manual is a block of text for typing the account in by hand on
+// those that cannot:
+//
+//	Issuer:    Example
+//	Account:   alice@example.com
+//	Secret:    JBSW Y3DP EHPK 3PXP
+//	Type:      Time based (TOTP)
+//	Digits:    6
+//	Period:    30 seconds
+//
+// Defaults are spelled out. HOTP keys show their counter instead of a
+// period, and an algorithm other than SHA1 gets its own line.
+func (k *Key) Enrollment() (link, manual string, err error) {
+	if link, err = k.URI(); err != nil {
+		return "", "", err
+	}
+	issuer, _ := NormalizeIssuer(k.Issuer)
+	digits, period := k.Digits, k.Period
+	if digits == 0 {
+		digits = DefaultDigits
+	}
+	if period == 0 {
+		period = int(DefaultPeriod / time.Second)
+	}
+
+	var b strings.Builder
+	line := func(name, value string) { fmt.Fprintf(&b, "%-10s %s\n", name+":", value) }
+	if issuer != "" {
+		line("Issuer", issuer)
+	}
+	line("Account", k.Label)
+	line("Secret", groupSecret(k.Secret))
+	if k.Type == TypeHOTP {
+		line("Type", "Counter based (HOTP)")
+	} else {
+		line("Type", "Time based (TOTP)")
+	}
+	if k.Algorithm != "" && !strings.EqualFold(k.Algorithm, DefaultAlgorithm) {
+		line("Algorithm", strings.ToUpper(k.Algorithm))
+	}
+	line("Digits", strconv.Itoa(digits))
+	if k.Type == TypeHOTP {
+		line("Counter", strconv.FormatUint(k.Counter, 10))
+	} else {
+		line("Period", strconv.Itoa(period)+" seconds")
+	}
+	return link, b.String(), nil
+}
+
+// groupSecret formats a base32 secret in blocks of four characters, without
+// padding, as authenticator apps accept it when typed in.
+func groupSecret(secret string) string {
+	secret = strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	secret = strings.TrimRight(secret, "=")
+	var b strings.Builder
+	for i := 0; i < len(secret); i += 4 {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		b.WriteString(secret[i:min(i+4, len(secret))])
+	}
+	return b.String()
+}
+
+// format writes the URI with the given issuer. Parameters are written in a
+// fixed order so the output is stable.
+func (k *Key) format(issuer string) string {
+	label := k.Label
+	prefix := k.LabelIssuer
+	if prefix == "" {
+		prefix = issuer
+	}
+	if prefix != "" && !strings.Contains(prefix, ":") {
+		label = prefix + ":" + label
+	}
+
+	params := [][2]string{{"secret", k.Secret}}
+	if issuer != "" {
+		params = append(params, [2]string{"issuer", issuer})
+	}
+	if k.Algorithm != "" {
+		params = append(params, [2]string{"algorithm", k.Algorithm})
+	}
+	if k.Digits != 0 {
+		params = append(params, [2]string{"digits", strconv.Itoa(k.Digits)})
+	}
+	if k.Period != 0 {
+		params = append(params, [2]string{"period", strconv.Itoa(k.Period)})
+	}
+	if k.Type == TypeHOTP || k.Counter != 0 {
+		params = append(params, [2]string{"counter", strconv.FormatUint(k.Counter, 10)})
+	}
+	names := make([]string, 0, len(k.Params))
+	for name := range k.Params {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, v := range k.Params[name] {
+			params = append(params, [2]string{name, v})
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("otpauth://")
+	b.WriteString(k.Type)
+	b.WriteByte('/')
+	b.WriteString(url.PathEscape(label))
+	for i, p := range params {
+		if i == 0 {
+			b.WriteByte('?')
+		} else {
+			b.WriteByte('&')
+		}
+		b.WriteString(url.PathEscape(p[0]))
+		b.WriteByte('=')
+		b.WriteString(queryEscape(p[1]))
+	}
+	return b.String()
+}
+
+// queryEscape escapes a query value using %20 for spaces, which
+// authenticator apps handle more reliably than '+'.
+func queryEscape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
@@ -0,0 +1,244 @@
+package g2fa
+
+import (
+	"net/url"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		uri  string
+		want Key
+	}{
+		{
+			"otpauth://totp/Example:alice@google.com?secret=JBSWY3DPEHPK3PXP&issuer=Example",
+			Key{Type: TypeTOTP, Label: "alice@google.com", Issuer: "Example", Secret: "JBSWY3DPEHPK3PXP"},
+		},
+		{
+			"otpauth://totp/ACME%20Co:%20john.doe@email.com?secret=HXDMVJECJJWSRB3HWIZR4IFUGFTMXBOZ&issuer=ACME%20Co&algorithm=SHA256&digits=8&period=60",
+			Key{Type: TypeTOTP, Label: "john.doe@email.com", Issuer: "ACME Co", Secret: "HXDMVJECJJWSRB3HWIZR4IFUGFTMXBOZ", Algorithm: "SHA256", Digits: 8, Period: 60},
+		},
+		{
+			"otpauth://hotp/bob?secret=AAAA&counter=42",
+			Key{Type: TypeHOTP, Label: "bob", Secret: "AAAA", Counter: 42},
+		},
+		{
+			"otpauth://totp/Foo:alice?secret=AAAA&issuer=Bar",
+			Key{Type: TypeTOTP, Label: "alice", Issuer: "Bar", LabelIssuer: "Foo", Secret: "AAAA"},
+		},
+		{
+			"otpauth://totp/x?secret=AAAA&image=https%3A%2F%2Fexample.com%2Flogo.png",
+			Key{Type: TypeTOTP, Label: "x", Secret: "AAAA", Params: url.Values{"image": {"https://example.com/logo.png"}}},
+		},
+	}
+	for _, tt := range tests {
+		got, err := ParseKey(tt.uri)
+		if err != nil {
+			t.Errorf("ParseKey(%q): %v", tt.uri, err)
+			continue
+		}
+		if !reflect.DeepEqual(*got, tt.want) {
+			t.Errorf("ParseKey(%q) = %+v, want %+v", tt.uri, *got, tt.want)
+		}
+	}
+}
+
+func TestParseKeyInvalid(t *testing.T) {
+	for _, uri := range []string{
+		"http://totp/a?secret=AAAA",
+		"otpauth://xotp/a?secret=AAAA",
+		"otpauth://totp/a",
+		"otpauth://hotp/a?secret=AAAA",
+		"otpauth://totp/a?secret=AAAA&digits=x",
+		"otpauth://totp/a?secret=AAAA&digits=0",
+		"otpauth://totp/a?secret=AAAA&period=-30",
+		"otpauth://hotp/a?secret=AAAA&counter=-1",
+	} {
+		if _, err := ParseKey(uri); err != ErrInvalidKeyURI {
+			t.Errorf("ParseKey(%q) error = %v, want ErrInvalidKeyURI", uri, err)
+		}
+	}
+}
+
+func TestKeyRoundTrip(t *testing.T) {
+	for _, uri := range []string{
+		"otpauth://totp/Example:alice@google.com?secret=JBSWY3DPEHPK3PXP&issuer=Example",
+		"otpauth://hotp/ACME%20Co:john%20doe?secret=AAAA&issuer=ACME%20Co&algorithm=SHA256&digits=8&counter=0&image=x%3Fy",
+		"otpauth://totp/bob?secret=AAAA&period=60",
+		"otpauth://totp/Foo:alice?secret=AAAA&issuer=Bar",
+		"otpauth://totp/x?secret=AAAA&counter=5",
+		"otpauth://totp/x?secret=AAAA&secret=BBBB",
+		"otpauth://totp/x?secret=AAAA&a=1&a=2&b=3",
+	} {
+		k, err := ParseKey(uri)
+		if err != nil {
+			t.Fatalf("ParseKey(%q): %v", uri, err)
+		}
+		again, err := ParseKey(k.String())
+		if err != nil {
+			t.Fatalf("ParseKey(%q): %v", k.String(), err)
+		}
+		if !reflect.DeepEqual(k, again) {
+			t.Errorf("%q round-trips as %q:\n%+v\n%+v", uri, k.String(), k, again)
+		}
+	}
+}
+
+func TestKeyString(t *testing.T) {
+	tests := []struct {
+		key  Key
+		want string
+	}{
+		{
+			Key{Type: TypeTOTP, Label: "alice@google.com", Issuer: "Example", Secret: "JBSWY3DPEHPK3PXP"},
+			"otpauth://totp/Example:alice@google.com?secret=JBSWY3DPEHPK3PXP&issuer=Example",
+		},
+		{
+			Key{Type: TypeHOTP, Label: "john doe", Issuer: "ACME Co", Secret: "AAAA"},
+			"otpauth://hotp/ACME%20Co:john%20doe?secret=AAAA&issuer=ACME%20Co&counter=0",
+		},
+		{
+			Key{Type: TypeTOTP, Label: "alice", Issuer: "Bar", LabelIssuer: "Foo", Secret: "AAAA", Counter: 5},
+			"otpauth://totp/Foo:alice?secret=AAAA&issuer=Bar&counter=5",
+		},
+	}
+	for _, tt := range tests {
+		if got := tt.key.String(); got != tt.want {
+			t.Errorf("String() = %q, want %q", got, tt.want)
+		}
+	}
+}
+
+func TestNormalizeIssuer(t *testing.T) {
+	tests := []struct {
+		in, want string
+		err      error
+	}{
+		{"ACME Corp", "ACME Corp", nil},
+		{"  ACME \t Corp ", "ACME Corp", nil},
+		{"", "", nil},
+		{"ACME:Corp", "", ErrInvalidIssuer},
+	}
+	for _, tt := range tests {
+		got, err := NormalizeIssuer(tt.in)
+		if got != tt.want || err != tt.err {
+			t.Errorf("NormalizeIssuer(%q) = %q, %v, want %q, %v", tt.in, got, err, tt.want, tt.err)
+		}
+	}
+}
+
+func TestCanonicalIssuerDomain(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"example.com", "example.com"},
+		{"https://WWW.Example.com:443/login", "example.com"},
+		{"login.example.co.uk.", "login.example.co.uk"},
+		{"www.ex.org?x=1", "ex.org"},
+		{"127.0.0.1:8443", "127.0.0.1"},
+		{"::1", "::1"},
+		{"[::1]", "::1"},
+		{"[::1]:443", "::1"},
+		{"http://[2001:DB8:0::1]/", "2001:db8::1"},
+	}
+	for _, tt := range tests {
+		if got := CanonicalIssuerDomain(tt.in); got != tt.want {
+			t.Errorf("CanonicalIssuerDomain(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestParseKeyIssuerNormalization(t *testing.T) {
+	k, err := ParseKey("otpauth://totp/a?secret=AAAA&issuer=%20ACME%20%20Corp")
+	if err != nil || k.Issuer != "ACME Corp" {
+		t.Errorf("ParseKey issuer = %+v, %v, want ACME Corp", k, err)
+	}
+	if _, err := ParseKey("otpauth://totp/a?secret=AAAA&issuer=ACME:Corp"); err != ErrInvalidIssuer {
+		t.Errorf("ParseKey with colon issuer error = %v, want ErrInvalidIssuer", err)
+	}
+}
+
+func TestKeyURI(t *testing.T) {
+	k := &Key{Type: TypeTOTP, Label: "alice", Issuer: " ACME  Corp", Secret: "AAAA"}
+	uri, err := k.URI()
+	if want := "otpauth://totp/ACME%20Corp:alice?secret=AAAA&issuer=ACME%20Corp"; err != nil || uri != want {
+		t.Errorf("URI() = %q, %v, want %q", uri, err, want)
+	}
+	if s := k.String(); s != uri {
+		t.Errorf("String() = %q, want %q", s, uri)
+	}
+
+	tests := []struct {
+		key  Key
+		want error
+	}{
+		{Key{Type: TypeTOTP, Label: "alice", Issuer: "A:B", Secret: "AAAA"}, ErrInvalidIssuer},
+		{Key{Type: TypeTOTP, Label: "alice", LabelIssuer: "A:B", Secret: "AAAA"}, ErrInvalidIssuer},
+		{Key{Type: "motp", Label: "alice", Secret: "AAAA"}, ErrInvalidKeyURI},
+		{Key{Type: TypeTOTP, Label: "alice"}, ErrInvalidKeyURI},
+	}
+	for _, tt := range tests {
+		if _, err := tt.key.URI(); err != tt.want {
+			t.Errorf("URI() for %+v error = %v, want %v", tt.key, err, tt.want)
+		}
+	}
+
+	// String keeps a colon issuer out of the label.
+	colon := &Key{Type: TypeTOTP, Label: "alice", Issuer: "A:B", Secret: "AAAA"}
+	if got, want := colon.String(), "otpauth://totp/alice?secret=AAAA&issuer=A%3AB"; got != want {
+		t.Errorf("String() = %q, want %q", got, want)
+	}
+}
+
+func TestKeyOptions(t *testing.T) {
+	k := &Key{Type: TypeTOTP, Secret: rfcSecretSHA256, Algorithm: "SHA256", Digits: 8, Period: 30}
+	code, err := GenerateTOTP(k.Secret, time.Unix(59, 0), k.Options()...)
+	if err != nil || code != "46119246" {
+		t.Errorf("GenerateTOTP with key options = %q, %v, want 46119246", code, err)
+	}
+	if opts := (&Key{Type: TypeTOTP, Secret: "AAAA"}).Options(); len(opts) != 0 {
+		t.Errorf("Options() for a key without parameters returned %d options", len(opts))
+	}
+}
+
+func TestKeyEnrollment(t *testing.T) {
+	k := &Key{Type: TypeTOTP, Label: "alice@example.com", Issuer: " Example ", Secret: "jbswy3dpehpk3pxp"}
+	link, manual, err := k.Enrollment()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, _ := k.URI(); link != want {
+		t.Errorf("link = %q, want %q", link, want)
+	}
+	want := "Issuer:    Example\n" +
+		"Account:   alice@example.com\n" +
+		"Secret:    JBSW Y3DP EHPK 3PXP\n" +
+		"Type:      Time based (TOTP)\n" +
+		"Digits:    6\n" +
+		"Period:    30 seconds\n"
+	if manual != want {
+		t.Errorf("manual =\n%s\nwant\n%s", manual, want)
+	}
+
+	h := &Key{Type: TypeHOTP, Label: "bob", Secret: "GEZDGNBVGY======", Algorithm: "sha256", Digits: 8, Counter: 7}
+	_, manual, err = h.Enrollment()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want = "Account:   bob\n" +
+		"Secret:    GEZD GNBV GY\n" +
+		"Type:      Counter based (HOTP)\n" +
+		"Algorithm: SHA256\n" +
+		"Digits:    8\n" +
+		"Counter:   7\n"
+	if manual != want {
+		t.Errorf("manual =\n%s\nwant\n%s", manual, want)
+	}
+
+	bad := &Key{Type: TypeTOTP, Label: "alice", Issuer: "A:B", Secret: "AAAA"}
+	if _, _, err := bad.Enrollment(); err != ErrInvalidIssuer {
+		t.Errorf("Enrollment with colon issuer error = %v, want ErrInvalidIssuer", err)
+	}
+}
@@ -0,0 +1,123 @@
+package g2fa
+
+import (
+	"errors"
+	"time"
+)
+
+// LoginState is a step of a LoginFlow.
+type LoginState int
+
+// Login flow states. A flow starts in LoginStart, moves to LoginOTPRequired
+// once the password is accepted and ends in LoginOTPVerified or LoginLocked.
+const (
+	LoginStart LoginState = iota
+	LoginOTPRequired
+	LoginOTPVerified
+	LoginLocked
+)
+
+var (
+	// ErrLoginState is returned when a step is taken out of order, such as
+	// submitting an OTP before the password was accepted.
+	ErrLoginState = errors.New("g2fa: login step out of order")
+	// ErrLoginExpired is returned when the OTP step was not completed
+	// before the flow's deadline. The flow returns to LoginStart.
+	ErrLoginExpired = errors.New("g2fa: login flow expired")
+	// ErrLoginCodeRejected is returned when a wrong OTP was submitted and
+	// further attempts remain.
+	ErrLoginCodeRejected = errors.New("g2fa: login code rejected")
+	// ErrLoginLocked is returned once too many wrong OTPs were submitted.
+	ErrLoginLocked = errors.New("g2fa: login flow locked")
+)
+
+// LoginFlow sequences a password + OTP login so that an OTP can only be
+// accepted after the password, within a deadline and a bounded number of
+// attempts. Its exported state fields can be stored between requests.
+type LoginFlow struct {
+	State    LoginState
+	Attempts int
+	Deadline time.Time
+
+	// MaxAttempts is the number of wrong OTPs allowed before the flow
+	// locks; zero means unlimited.
+	MaxAttempts int
+	// Timeout is how long the OTP step stays open after the password is
+	// accepted; zero means no deadline.
+	Timeout time.Duration
+
+	// Persist, if set, is called with the updated flow before every change
+	// is applied, so it can be saved. An error aborts the change and is
+	// returned.
+	Persist func(LoginFlow) error `json:"-"`
+}
+
+// PasswordOK records that the password was verified at now and opens the
+// OTP step.
+func (f *LoginFlow) PasswordOK(now time.Time) error {
+	if f.State != LoginStart {
+		return ErrLoginState
+	}
+	return f.update(func(n *LoginFlow) {
+		n.State, n.Attempts, n.Deadline = LoginOTPRequired, 0, time.Time{}
+		if f.Timeout > 0 {
+			n.Deadline = now.Add(f.Timeout)
+		}
+	})
+}
+
+// OTPResult records the outcome of an OTP check made at now. A nil error
+// means the flow reached LoginOTPVerified; a wrong code returns
+// ErrLoginCodeRejected, or ErrLoginLocked once MaxAttempts is reached.
+func (f *LoginFlow) OTPResult(ok bool, now time.Time) error {
+	switch f.State {
+	case LoginOTPRequired:
+	case LoginLocked:
+		return ErrLoginLocked
+	default:
+		return ErrLoginState
+	}
+	if !f.Deadline.IsZero() && now.After(f.Deadline) {
+		if err := f.update(func(n *LoginFlow) { n.State = LoginStart }); err != nil {
+			return err
+		}
+		return ErrLoginExpired
+	}
+	if ok {
+		return f.update(func(n *LoginFlow) { n.State = LoginOTPVerified })
+	}
+
+	locked := f.MaxAttempts > 0 && f.Attempts+1 >= f.MaxAttempts
+	err := f.update(func(n *LoginFlow) {
+		n.Attempts++
+		if locked {
+			n.State = LoginLocked
+		}
+	})
+	switch {
+	case err != nil:
+		return err
+	case locked:
+		return ErrLoginLocked
+	}
+	return ErrLoginCodeRejected
+}
+
+// Verified reports whether the flow completed both factors.
+func (f *LoginFlow) Verified() bool {
+	return f.State == LoginOTPVerified
+}
+
+// update applies change to a copy of the flow, persists it and only then
+// commits it to f.
+func (f *LoginFlow) update(change func(*LoginFlow)) error {
+	n := *f
+	change(&n)
+	if f.Persist != nil {
+		if err := f.Persist(n); err != nil {
+			return err
+		}
+	}
+	*f = n
+	return nil
+}
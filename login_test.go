@@ -0,0 +1,82 @@
+package g2fa
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestLoginFlow(t *testing.T) {
+	now := time.Unix(1000, 0)
+	var saved []LoginFlow
+	f := &LoginFlow{
+		MaxAttempts: 3,
+		Timeout:     time.Minute,
+		Persist:     func(s LoginFlow) error { saved = append(saved, s); return nil },
+	}
+
+	if err := f.OTPResult(true, now); err != ErrLoginState {
+		t.Fatalf("OTP before password: error = %v, want ErrLoginState", err)
+	}
+	if err := f.PasswordOK(now); err != nil {
+		t.Fatal(err)
+	}
+	if f.State != LoginOTPRequired || !f.Deadline.Equal(now.Add(time.Minute)) {
+		t.Fatalf("after PasswordOK: %+v", f)
+	}
+	if err := f.PasswordOK(now); err != ErrLoginState {
+		t.Fatalf("second PasswordOK: error = %v, want ErrLoginState", err)
+	}
+	if err := f.OTPResult(false, now); err != ErrLoginCodeRejected || f.Attempts != 1 {
+		t.Fatalf("wrong OTP: error = %v, attempts = %d", err, f.Attempts)
+	}
+	if err := f.OTPResult(true, now.Add(time.Second)); err != nil || !f.Verified() {
+		t.Fatalf("right OTP: error = %v, state = %v", err, f.State)
+	}
+	if len(saved) != 3 || saved[2].State != LoginOTPVerified || saved[1].Attempts != 1 {
+		t.Errorf("persisted states = %+v", saved)
+	}
+}
+
+func TestLoginFlowLock(t *testing.T) {
+	now := time.Unix(1000, 0)
+	f := &LoginFlow{MaxAttempts: 2}
+	f.PasswordOK(now)
+	if err := f.OTPResult(false, now); err != ErrLoginCodeRejected {
+		t.Fatalf("first wrong OTP: error = %v", err)
+	}
+	if err := f.OTPResult(false, now); err != ErrLoginLocked || f.State != LoginLocked || f.Attempts != 2 {
+		t.Fatalf("second wrong OTP: error = %v, %+v", err, f)
+	}
+	if err := f.OTPResult(true, now); err != ErrLoginLocked {
+		t.Fatalf("OTP on locked flow: error = %v, want ErrLoginLocked", err)
+	}
+}
+
+func TestLoginFlowExpiry(t *testing.T) {
+	now := time.Unix(1000, 0)
+	f := &LoginFlow{Timeout: time.Second}
+	f.PasswordOK(now)
+	if err := f.OTPResult(true, now.Add(2*time.Second)); err != ErrLoginExpired || f.State != LoginStart {
+		t.Fatalf("late OTP: error = %v, state = %v", err, f.State)
+	}
+	f.PasswordOK(now)
+	if err := f.OTPResult(true, now.Add(time.Second)); err != nil || !f.Verified() {
+		t.Fatalf("OTP at the deadline: error = %v", err)
+	}
+}
+
+func TestLoginFlowPersistError(t *testing.T) {
+	errStore := errors.New("store down")
+	f := &LoginFlow{Persist: func(LoginFlow) error { return errStore }}
+	if err := f.PasswordOK(time.Unix(0, 0)); err != errStore || f.State != LoginStart {
+		t.Fatalf("PasswordOK error = %v, state = %v", err, f.State)
+	}
+
+	f = &LoginFlow{MaxAttempts: 1}
+	f.PasswordOK(time.Unix(0, 0))
+	f.Persist = func(LoginFlow) error { return errStore }
+	if err := f.OTPResult(false, time.Unix(0, 0)); err != errStore || f.State != LoginOTPRequired || f.Attempts != 0 {
+		t.Fatalf("OTPResult error = %v, %+v", err, f)
+	}
+}
@@ -0,0 +1,326 @@
+package g2fa
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"net/url"
+	"strings"
+)
+
+// MigrationBatchSize is the number of accounts EncodeMigrationURIs puts in
+// each otpauth-migration:// URI, small enough for the resulting QR code to
+// scan reliably.
+const MigrationBatchSize = 10
+
+// ErrInvalidMigrationURI is returned by DecodeMigrationURI when the URI or
+// its protobuf payload is malformed.
+var ErrInvalidMigrationURI = errors.New("g2fa: invalid otpauth-migration URI")
+
+// ErrNoMigrationKeys is returned by EncodeMigrationURIs when given no keys.
+var ErrNoMigrationKeys = errors.New("g2fa: no keys to migrate")
+
+// ErrUnsupportedMigration is returned when a key uses parameters that the
+// Google Authenticator migration format cannot express, such as a period
+// other than 30 seconds or a digit count other than 6 or 8.
+var ErrUnsupportedMigration = errors.New("g2fa: key not representable in migration format")
+
+// Enum values of the Google Authenticator MigrationPayload protobuf.
+const (
+	migrationAlgoSHA1   = 1
+	migrationAlgoSHA256 = 2
+	migrationAlgoSHA512 = 3
+	migrationAlgoMD5    = 4
+
+	migrationDigitsSix   = 1
+	migrationDigitsEight = 2
+
+	migrationTypeHOTP = 1
+	migrationTypeTOTP = 2
+)
+
+// EncodeMigrationURIs encodes keys as otpauth-migration://offline URIs, the
+// format of Google Authenticator's "Transfer accounts" QR codes. Keys are
+// split over several URIs of at most MigrationBatchSize accounts each; all of
+// them must be scanned to import every account.
+func EncodeMigrationURIs(keys []*Key) ([]string, error) {
+	if len(keys) == 0 {
+		return nil, ErrNoMigrationKeys
+	}
+	params := make([][]byte, len(keys))
+	for i, k := range keys {
+		p, err := marshalMigrationKey(k)
+		if err != nil {
+			return nil, err
+		}
+		params[i] = p
+	}
+
+	var id [4]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return nil, err
+	}
+	batchID := uint64(binary.BigEndian.Uint32(id[:]) &^ (1 << 31))
+	batchSize := (len(params) + MigrationBatchSize - 1) / MigrationBatchSize
+
+	uris := make([]string, 0, batchSize)
+	for i := 0; i < len(params); i += MigrationBatchSize {
+		end := i + MigrationBatchSize
+		if end > len(params) {
+			end = len(params)
+		}
+		var payload []byte
+		for _, p := range params[i:end] {
+			payload = appendBytesField(payload, 1, p)
+		}
+		payload = appendVarintField(payload, 2, 1)
+		payload = appendVarintField(payload, 3, uint64(batchSize))
+		payload = appendVarintField(payload, 4, uint64(i/MigrationBatchSize))
+		payload = appendVarintField(payload, 5, batchID)
+
+		data := url.QueryEscape(base64.StdEncoding.EncodeToString(payload))
+		uris = append(uris, "otpauth-migration://offline?data="+data)
+	}
+	return uris, nil
+}
+
+// DecodeMigrationURI decodes an otpauth-migration://offline URI exported by
+// Google Authenticator into keys. When the export spans several QR codes,
+// each URI holds only its own batch of accounts.
+func DecodeMigrationURI(uri string) ([]*Key, error) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "otpauth-migration" || u.Host != "offline" {
+		return nil, ErrInvalidMigrationURI
+	}
+	// Some exporters leave '+' unescaped, which query decoding turns into
+	// a space.
+	data := strings.ReplaceAll(u.Query().Get("data"), " ", "+")
+	payload, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		if payload, err = base64.RawStdEncoding.DecodeString(strings.TrimRight(data, "=")); err != nil {
+			return nil, ErrInvalidMigrationURI
+		}
+	}
+
+	var keys []*Key
+	err = walkFields(payload, func(field int, _ uint64, v []byte) error {
+		if field != 1 {
+			return nil
+		}
+		k, err := unmarshalMigrationKey(v)
+		if err != nil {
+			return err
+		}
+		keys = append(keys, k)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func unmarshalMigrationKey(b []byte) (*Key, error) {
+	k := &Key{}
+	var name string
+	var secret []byte
+	err := walkFields(b, func(field int, n uint64, v []byte) error {
+		switch field {
+		case 1:
+			secret = v
+		case 2:
+			name = string(v)
+		case 3:
+			k.Issuer = string(v)
+		case 4:
+			switch n {
+			case migrationAlgoSHA1:
+				k.Algorithm = "SHA1"
+			case migrationAlgoSHA256:
+				k.Algorithm = "SHA256"
+			case migrationAlgoSHA512:
+				k.Algorithm = "SHA512"
+			case migrationAlgoMD5:
+				k.Algorithm = "MD5"
+			}
+		case 5:
+			switch n {
+			case migrationDigitsSix:
+				k.Digits = 6
+			case migrationDigitsEight:
+				k.Digits = 8
+			}
+		case 6:
+			switch n {
+			case migrationTypeHOTP:
+				k.Type = TypeHOTP
+			case migrationTypeTOTP:
+				k.Type = TypeTOTP
+			}
+		case 7:
+			k.Counter = n
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(secret) == 0 || k.Type == "" {
+		return nil, ErrInvalidMigrationURI
+	}
+	k.Secret = encodeSecret(secret)
+
+	// Names are often "Issuer:account"; split them as ParseKey does with
+	// labels, and normalize the issuer the same way.
+	var prefix string
+	if i := strings.Index(name, ":"); i >= 0 {
+		prefix, name = name[:i], strings.TrimLeft(name[i+1:], " ")
+	}
+	if k.Issuer == "" {
+		k.Issuer = prefix
+	}
+	if k.Issuer, err = NormalizeIssuer(k.Issuer); err != nil {
+		return nil, err
+	}
+	if prefix != k.Issuer {
+		k.LabelIssuer = prefix
+	}
+	k.Label = name
+	return k, nil
+}
+
+func marshalMigrationKey(k *Key) ([]byte, error) {
+	secret, err := decodeSecret(k.Secret)
+	if err != nil {
+		return nil, err
+	}
+	if k.Period != 0 && k.Period != 30 {
+		return nil, ErrUnsupportedMigration
+	}
+
+	var algo uint64
+	switch strings.ToUpper(k.Algorithm) {
+	case "", "SHA1":
+		algo = migrationAlgoSHA1
+	case "SHA256":
+		algo = migrationAlgoSHA256
+	case "SHA512":
+		algo = migrationAlgoSHA512
+	case "MD5":
+		algo = migrationAlgoMD5
+	default:
+		return nil, ErrUnsupportedMigration
+	}
+
+	var digits uint64
+	switch k.Digits {
+	case 0, 6:
+		digits = migrationDigitsSix
+	case 8:
+		digits = migrationDigitsEight
+	default:
+		return nil, ErrUnsupportedMigration
+	}
+
+	var typ uint64
+	switch k.Type {
+	case TypeHOTP:
+		typ = migrationTypeHOTP
+	case TypeTOTP:
+		typ = migrationTypeTOTP
+	default:
+		return nil, ErrUnsupportedMigration
+	}
+
+	var b []byte
+	b = appendBytesField(b, 1, secret)
+	name := k.Label
+	if k.LabelIssuer != "" {
+		name = k.LabelIssuer + ":" + name
+	}
+	b = appendBytesField(b, 2, []byte(name))
+	b = appendBytesField(b, 3, []byte(k.Issuer))
+	b = appendVarintField(b, 4, algo)
+	b = appendVarintField(b, 5, digits)
+	b = appendVarintField(b, 6, typ)
+	if typ == migrationTypeHOTP {
+		b = appendVarintField(b, 7, k.Counter)
+	}
+	wipe(secret)
+	return b, nil
+}
+
+// Minimal protobuf wire format support, enough for MigrationPayload.
+
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// walkFields calls fn for every field of the protobuf message b. Varint
+// fields are passed as n, length-delimited ones as v; fixed-size fields are
+// skipped.
+func walkFields(b []byte, fn func(field int, n uint64, v []byte) error) error {
+	for len(b) > 0 {
+		tag, l := binary.Uvarint(b)
+		if l <= 0 {
+			return ErrInvalidMigrationURI
+		}
+		b = b[l:]
+		field := int(tag >> 3)
+
+		var n uint64
+		var v []byte
+		switch tag & 7 {
+		case wireVarint:
+			if n, l = binary.Uvarint(b); l <= 0 {
+				return ErrInvalidMigrationURI
+			}
+			b = b[l:]
+		case wireBytes:
+			size, l := binary.Uvarint(b)
+			if l <= 0 || size > uint64(len(b)-l) {
+				return ErrInvalidMigrationURI
+			}
+			v, b = b[l:l+int(size)], b[l+int(size):]
+		case wireFixed64:
+			if len(b) < 8 {
+				return ErrInvalidMigrationURI
+			}
+			b = b[8:]
+			continue
+		case wireFixed32:
+			if len(b) < 4 {
+				return ErrInvalidMigrationURI
+			}
+			b = b[4:]
+			continue
+		default:
+			return ErrInvalidMigrationURI
+		}
+		if err := fn(field, n, v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func appendVarintField(b []byte, field int, v uint64) []byte {
+	b = binary.AppendUvarint(b, uint64(field)<<3|wireVarint)
+	return binary.AppendUvarint(b, v)
+}
+
+func appendBytesField(b []byte, field int, v []byte) []byte {
+	b = binary.AppendUvarint(b, uint64(field)<<3|wireBytes)
+	b = binary.AppendUvarint(b, uint64(len(v)))
+	return append(b, v...)
+}
+
+// encodeSecret is the inverse of decodeSecret, producing the unpadded
+// base32 form used in otpauth URIs.
+func encodeSecret(secret []byte) string {
+	return RawSecretEncoding.EncodeToString(secret)
+}
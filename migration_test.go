@@ -0,0 +1,159 @@
+package g2fa
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// migrationPayload decodes the protobuf payload of a migration URI.
+func migrationPayload(t *testing.T, uri string) []byte {
+	t.Helper()
+	data := strings.TrimPrefix(uri, "otpauth-migration://offline?data=")
+	data, err := url.QueryUnescape(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload, err := base64.StdEncoding.DecodeString(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return payload
+}
+
+func TestEncodeMigrationURIs(t *testing.T) {
+	key := &Key{Type: TypeTOTP, Label: "alice@google.com", Issuer: "Example", Secret: "JBSWY3DPEHPK3PXP"}
+	uris, err := EncodeMigrationURIs([]*Key{key})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(uris) != 1 || !strings.HasPrefix(uris[0], "otpauth-migration://offline?data=") {
+		t.Fatalf("EncodeMigrationURIs = %q", uris)
+	}
+
+	// OtpParameters: secret, name, issuer, SHA1, six digits, TOTP.
+	want := "\x0a\x0aHello!\xde\xad\xbe\xef" +
+		"\x12\x10alice@google.com" +
+		"\x1a\x07Example" +
+		"\x20\x01\x28\x01\x30\x02"
+	payload := migrationPayload(t, uris[0])
+	if !strings.HasPrefix(string(payload), fmt.Sprintf("\x0a%c%s", len(want), want)) {
+		t.Errorf("payload = %q, want OtpParameters %q", payload, want)
+	}
+	// version 1, batch_size 1, batch_index 0, then batch_id.
+	if rest := payload[2+len(want):]; !strings.HasPrefix(string(rest), "\x10\x01\x18\x01\x20\x00\x28") {
+		t.Errorf("payload trailer = %q", rest)
+	}
+}
+
+func TestEncodeMigrationURIsBatches(t *testing.T) {
+	var keys []*Key
+	for i := 0; i < 2*MigrationBatchSize+1; i++ {
+		keys = append(keys, &Key{Type: TypeTOTP, Label: fmt.Sprint("user", i), Secret: "JBSWY3DPEHPK3PXP"})
+	}
+	uris, err := EncodeMigrationURIs(keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(uris) != 3 {
+		t.Fatalf("got %d URIs, want 3", len(uris))
+	}
+}
+
+func TestEncodeMigrationURIsInvalid(t *testing.T) {
+	if _, err := EncodeMigrationURIs(nil); err != ErrNoMigrationKeys {
+		t.Errorf("EncodeMigrationURIs(nil) error = %v, want ErrNoMigrationKeys", err)
+	}
+	for _, k := range []*Key{
+		{Type: TypeTOTP, Secret: "AAAA", Period: 60},
+		{Type: TypeTOTP, Secret: "AAAA", Digits: 7},
+		{Type: TypeTOTP, Secret: "AAAA", Algorithm: "SHA3"},
+		{Type: "motp", Secret: "AAAA"},
+	} {
+		if _, err := EncodeMigrationURIs([]*Key{k}); err != ErrUnsupportedMigration {
+			t.Errorf("EncodeMigrationURIs(%+v) error = %v, want ErrUnsupportedMigration", k, err)
+		}
+	}
+	if _, err := EncodeMigrationURIs([]*Key{{Type: TypeTOTP, Secret: "not base32!"}}); err == nil {
+		t.Error("EncodeMigrationURIs accepted an invalid secret")
+	}
+}
+
+func TestDecodeMigrationURI(t *testing.T) {
+	// Exported by Google Authenticator for a single TOTP account.
+	uri := "otpauth-migration://offline?data=CjEKCkhlbGxvId6tvu8SGEV4YW1wbGU6YWxpY2VAZ29vZ2xlLmNvbRoHRXhhbXBsZTAC"
+	keys, err := DecodeMigrationURI(uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := Key{Type: TypeTOTP, Label: "alice@google.com", Issuer: "Example", Secret: "JBSWY3DPEHPK3PXP"}
+	if len(keys) != 1 || !reflect.DeepEqual(*keys[0], want) {
+		t.Fatalf("DecodeMigrationURI = %+v, want %+v", keys, want)
+	}
+
+	// Unescaped '+' in the data parameter.
+	plus := "otpauth-migration://offline?data=CgcKA/gA+DAC"
+	if keys, err := DecodeMigrationURI(plus); err != nil || len(keys) != 1 || keys[0].Secret != "7AAPQ" {
+		t.Errorf("DecodeMigrationURI(%q) = %+v, %v", plus, keys, err)
+	}
+}
+
+func TestDecodeMigrationURIInvalid(t *testing.T) {
+	payload := func(p string) string {
+		return "otpauth-migration://offline?data=" + url.QueryEscape(base64.StdEncoding.EncodeToString([]byte(p)))
+	}
+	for _, uri := range []string{
+		"otpauth://totp/a?secret=AAAA",
+		"otpauth-migration://online?data=",
+		"otpauth-migration://offline?data=!!!",
+		payload("\x0a\x31\x0a"),           // truncated OtpParameters
+		payload("\x0a\x02\x30\x02"),       // no secret
+		payload("\x0a\x05\x0a\x03abc"),    // no type
+		payload("\x0a\x04\x0a\x02ab\x07"), // unknown wire type
+	} {
+		if _, err := DecodeMigrationURI(uri); err != ErrInvalidMigrationURI {
+			t.Errorf("DecodeMigrationURI(%q) error = %v, want ErrInvalidMigrationURI", uri, err)
+		}
+	}
+
+	colon := payload("\x0a\x0b\x0a\x02ab\x1a\x03a:b\x30\x02")
+	if _, err := DecodeMigrationURI(colon); err != ErrInvalidIssuer {
+		t.Errorf("DecodeMigrationURI with issuer %q error = %v, want ErrInvalidIssuer", "a:b", err)
+	}
+}
+
+func TestMigrationRoundTrip(t *testing.T) {
+	keys := []*Key{
+		{Type: TypeTOTP, Label: "alice@example.com", Issuer: "Example", Secret: "JBSWY3DPEHPK3PXP", Algorithm: "SHA1", Digits: 6},
+		{Type: TypeHOTP, Label: "bob", Issuer: "ACME Co", Secret: "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", Algorithm: "SHA256", Digits: 8, Counter: 77},
+		{Type: TypeTOTP, Label: "carol", Issuer: "Bar", LabelIssuer: "Foo", Secret: "AAAAAAAA", Algorithm: "SHA512", Digits: 6},
+		{Type: TypeTOTP, Label: "dave", Secret: "MFRGGZDF", Algorithm: "MD5", Digits: 8},
+	}
+	for i := 0; i < MigrationBatchSize; i++ {
+		keys = append(keys, &Key{Type: TypeTOTP, Label: fmt.Sprint("user", i), Issuer: "Batch", Secret: "JBSWY3DPEHPK3PXP", Algorithm: "SHA1", Digits: 6})
+	}
+
+	uris, err := EncodeMigrationURIs(keys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []*Key
+	for _, uri := range uris {
+		batch, err := DecodeMigrationURI(uri)
+		if err != nil {
+			t.Fatalf("DecodeMigrationURI(%q): %v", uri, err)
+		}
+		got = append(got, batch...)
+	}
+	if !reflect.DeepEqual(got, keys) {
+		for i := range keys {
+			if i < len(got) && !reflect.DeepEqual(got[i], keys[i]) {
+				t.Errorf("key %d = %+v, want %+v", i, got[i], keys[i])
+			}
+		}
+		t.Fatalf("round trip returned %d keys, want %d", len(got), len(keys))
+	}
+}
@@ -0,0 +1,49 @@
+package g2fa
+
+import (
+	"strings"
+	"unicode"
+)
+
+// NormalizeCode cleans a code as typed by a user so it can be compared
+// against a generated one. Surrounding whitespace is trimmed, separators
+// (spaces and dashes, e.g. "123 456" or "123-456") are removed and decimal
+// digits from any script (Arabic-Indic, full-width, ...) are mapped to their
+// ASCII equivalents. Any other character is kept as-is so that validation
+// still rejects it.
+func NormalizeCode(code string) string {
+	code = strings.TrimSpace(code)
+
+	var b strings.Builder
+	b.Grow(len(code))
+	for _, r := range code {
+		switch {
+		case r >= '0' && r <= '9':
+			b.WriteRune(r)
+		case unicode.IsSpace(r) || unicode.Is(unicode.Pd, r):
+			// separator, drop it
+		case unicode.IsDigit(r):
+			b.WriteByte('0' + digitValue(r))
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
+
+// digitValue returns the numeric value of a decimal digit rune. Every range
+// in the Unicode Nd category is made of consecutive runs of ten digits
+// starting at zero, so the value is the offset from the range start modulo 10.
+func digitValue(r rune) byte {
+	for _, rg := range unicode.Nd.R16 {
+		if r >= rune(rg.Lo) && r <= rune(rg.Hi) {
+			return byte((r - rune(rg.Lo)) % 10)
+		}
+	}
+	for _, rg := range unicode.Nd.R32 {
+		if r >= rune(rg.Lo) && r <= rune(rg.Hi) {
+			return byte((r - rune(rg.Lo)) % 10)
+		}
+	}
+	return 0
+}
@@ -0,0 +1,27 @@
+package g2fa
+
+import "testing"
+
+func TestNormalizeCode(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"123456", "123456"},
+		{" 123456\n", "123456"},
+		{"123 456", "123456"},
+		{"123-456", "123456"},
+		{"123–456", "123456"},
+		{"١٢٣٤٥٦", "123456"},
+		{"۱۲۳۴۵۶", "123456"},
+		{"１２３４５６", "123456"},
+		{"१२३४५६", "123456"},
+		{"𝟏𝟐𝟑𝟒𝟓𝟔", "123456"},
+		{"12a456", "12a456"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := NormalizeCode(tt.in); got != tt.want {
+			t.Errorf("NormalizeCode(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,323 @@
+package g2fa
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrInvalidCode is wrapped by the errors returned when a submitted code
+	// is not made of the configured number of decimal digits.
+	ErrInvalidCode = errors.New("g2fa: invalid code format")
+	// ErrUnsupportedAlgorithm is returned for unknown HMAC algorithms.
+	ErrUnsupportedAlgorithm = errors.New("g2fa: unsupported algorithm")
+	// ErrInvalidDigits is returned for code lengths outside 6 to 8.
+	ErrInvalidDigits = errors.New("g2fa: digits must be between 6 and 8")
+	// ErrInvalidPeriod is returned for TOTP periods that are not a positive
+	// whole number of seconds.
+	ErrInvalidPeriod = errors.New("g2fa: period must be a positive number of seconds")
+	// ErrInvalidWindow is returned for windows outside 0 to MaxWindow.
+	ErrInvalidWindow = errors.New("g2fa: window must be between 0 and 100")
+	// ErrInvalidTime is returned for times before the Unix epoch, which have
+	// no TOTP time step.
+	ErrInvalidTime = errors.New("g2fa: time before Unix epoch")
+)
+
+// ErrBadLength is returned when a code has the wrong number of characters
+// after NormalizeCode. It wraps ErrInvalidCode.
+type ErrBadLength struct {
+	Got, Want int
+}
+
+func (e *ErrBadLength) Error() string {
+	return fmt.Sprintf("g2fa: code has %d digits, want %d", e.Got, e.Want)
+}
+
+func (e *ErrBadLength) Unwrap() error { return ErrInvalidCode }
+
+// ErrNonDigit is returned when a code contains a character that is not a
+// decimal digit. Pos is the zero-based character index in the normalized
+// code. It wraps ErrInvalidCode.
+type ErrNonDigit struct {
+	Pos int
+}
+
+func (e *ErrNonDigit) Error() string {
+	return fmt.Sprintf("g2fa: code has a non-digit at position %d", e.Pos)
+}
+
+func (e *ErrNonDigit) Unwrap() error { return ErrInvalidCode }
+
+// Defaults used when no Option overrides them. They match what
+// authenticator apps assume when an otpauth URI omits the parameter.
+const (
+	DefaultAlgorithm = "SHA1"
+	DefaultDigits    = 6
+	DefaultPeriod    = 30 * time.Second
+)
+
+// MaxWindow is the largest window accepted by WithWindow.
+const MaxWindow = 100
+
+type otpOptions struct {
+	algorithm string
+	digits    int
+	period    time.Duration
+	window    int
+	encoding  *SecretEncoding
+}
+
+// Option customises code generation and validation.
+type Option func(*otpOptions)
+
+// WithAlgorithm sets the HMAC algorithm: SHA1, SHA256 or SHA512. MD5, which
+// the Google Authenticator migration format can name, is rejected with
+// ErrUnsupportedAlgorithm: its 16 byte digest is too short for dynamic
+// truncation.
+func WithAlgorithm(name string) Option {
+	return func(o *otpOptions) { o.algorithm = name }
+}
+
+// WithDigits sets the code length, 6 to 8.
+func WithDigits(digits int) Option {
+	return func(o *otpOptions) { o.digits = digits }
+}
+
+// WithPeriod sets the TOTP time step. It is ignored for HOTP.
+func WithPeriod(period time.Duration) Option {
+	return func(o *otpOptions) { o.period = period }
+}
+
+// WithWindow sets how many steps around the expected one are accepted by
+// validation. For TOTP the window applies on both sides of the current step
+// (default 1), for HOTP it is the number of counters checked ahead of the
+// given one (default 0). Values outside 0 to MaxWindow are rejected with
+// ErrInvalidWindow.
+func WithWindow(steps int) Option {
+	return func(o *otpOptions) { o.window = steps }
+}
+
+// WithSecretEncoding sets the base32 variant secrets are decoded with. The
+// default is StdSecretEncoding.
+func WithSecretEncoding(enc *SecretEncoding) Option {
+	return func(o *otpOptions) { o.encoding = enc }
+}
+
+// GenerateTOTP returns the TOTP (RFC 6238) code for the base32 secret at
+// time t, zero-padded to the configured number of digits.
+func GenerateTOTP(secret string, t time.Time, opts ...Option) (string, error) {
+	var o otpOptions
+	p, err := newOTPParams(secret, &o, opts)
+	if err != nil {
+		return "", err
+	}
+	step, err := timeStep(t, o.period)
+	if err != nil {
+		return "", err
+	}
+	return p.code(step), nil
+}
+
+// GenerateHOTP returns the HOTP (RFC 4226) code for the base32 secret and
+// counter, zero-padded to the configured number of digits. The counter is
+// the moving factor, hashed as 8 bytes in big-endian order; it is unsigned,
+// so negative counters cannot be expressed. TOTP uses the time step as the
+// counter and rejects times before the Unix epoch with ErrInvalidTime.
+func GenerateHOTP(secret string, counter uint64, opts ...Option) (string, error) {
+	var o otpOptions
+	p, err := newOTPParams(secret, &o, opts)
+	if err != nil {
+		return "", err
+	}
+	return p.code(counter), nil
+}
+
+// TimeRemaining returns how long the TOTP code current at time t stays
+// valid, for countdown displays. Only WithPeriod is taken into account.
+// TOTP steps are counted in Unix time, so the location of t does not matter.
+func TimeRemaining(t time.Time, opts ...Option) (time.Duration, error) {
+	o := otpOptions{period: DefaultPeriod}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	step, err := timeStep(t, o.period)
+	if err != nil {
+		return 0, err
+	}
+	end := time.Unix(int64(step+1)*int64(o.period/time.Second), 0)
+	return end.Sub(t), nil
+}
+
+// ValidateTOTP checks code against the TOTP (RFC 6238) derived from the
+// base32 secret at time t. It keeps no state: on success it returns the
+// matched time step, which callers should record and refuse to accept again
+// to prevent replays.
+func ValidateTOTP(secret, code string, t time.Time, opts ...Option) (step uint64, ok bool, err error) {
+	o := otpOptions{window: 1}
+	p, err := newOTPParams(secret, &o, opts)
+	if err != nil {
+		return 0, false, err
+	}
+	current, err := timeStep(t, o.period)
+	if err != nil {
+		return 0, false, err
+	}
+	if code, err = p.parseCode(code); err != nil {
+		return 0, false, err
+	}
+
+	for i := -o.window; i <= o.window; i++ {
+		if i < 0 && uint64(-i) > current {
+			continue
+		}
+		s := current + uint64(i)
+		if p.matches(code, s) {
+			return s, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// ValidateHOTP checks code against the HOTP (RFC 4226) values derived from
+// the base32 secret for counter and, with WithWindow, the counters after it.
+// It keeps no state: on success it returns the matched counter, and callers
+// should store counter+1 as the next expected value.
+func ValidateHOTP(secret, code string, counter uint64, opts ...Option) (matched uint64, ok bool, err error) {
+	var o otpOptions
+	p, err := newOTPParams(secret, &o, opts)
+	if err != nil {
+		return 0, false, err
+	}
+	if code, err = p.parseCode(code); err != nil {
+		return 0, false, err
+	}
+
+	for i := 0; i <= o.window; i++ {
+		c := counter + uint64(i)
+		if c < counter {
+			break
+		}
+		if p.matches(code, c) {
+			return c, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// timeStep returns the TOTP time step containing t.
+func timeStep(t time.Time, period time.Duration) (uint64, error) {
+	if period < time.Second || period%time.Second != 0 {
+		return 0, ErrInvalidPeriod
+	}
+	if t.Unix() < 0 {
+		return 0, ErrInvalidTime
+	}
+	return uint64(t.Unix()) / uint64(period/time.Second), nil
+}
+
+// otpParams holds the HMAC keyed with the decoded secret and the resolved
+// options. The HMAC is reset between candidate steps rather than rebuilt,
+// so the inner and outer key pads are computed once per call.
+type otpParams struct {
+	mac    hash.Hash
+	digits int
+	sum    [sha512.Size]byte
+}
+
+func newOTPParams(secret string, o *otpOptions, opts []Option) (*otpParams, error) {
+	o.algorithm, o.digits, o.period = DefaultAlgorithm, DefaultDigits, DefaultPeriod
+	o.encoding = StdSecretEncoding
+	for _, opt := range opts {
+		opt(o)
+	}
+	h, err := hashFor(o.algorithm)
+	if err != nil {
+		return nil, err
+	}
+	if o.digits < 6 || o.digits > 8 {
+		return nil, ErrInvalidDigits
+	}
+	if o.window < 0 || o.window > MaxWindow {
+		return nil, ErrInvalidWindow
+	}
+	if o.encoding == nil {
+		o.encoding = StdSecretEncoding
+	}
+	key, err := o.encoding.DecodeString(secret)
+	if err != nil {
+		return nil, err
+	}
+	mac := hmac.New(h, key)
+	wipe(key)
+	return &otpParams{mac: mac, digits: o.digits}, nil
+}
+
+// parseCode normalizes code and checks it is made of the expected number of
+// digits, returning an *ErrNonDigit or *ErrBadLength otherwise.
+func (p *otpParams) parseCode(code string) (string, error) {
+	code = NormalizeCode(code)
+	n := 0
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return "", &ErrNonDigit{Pos: n}
+		}
+		n++
+	}
+	if n != p.digits {
+		return "", &ErrBadLength{Got: n, Want: p.digits}
+	}
+	return code, nil
+}
+
+func (p *otpParams) matches(code string, counter uint64) bool {
+	var want [8]byte
+	p.truncate(counter, want[:p.digits])
+	return subtle.ConstantTimeCompare([]byte(code), want[:p.digits]) == 1
+}
+
+// code returns the zero-padded HOTP value for counter.
+func (p *otpParams) code(counter uint64) string {
+	var out [8]byte
+	p.truncate(counter, out[:p.digits])
+	return string(out[:p.digits])
+}
+
+// truncate writes the HOTP value for counter into out as ASCII digits,
+// using dynamic truncation on the last byte of the digest so every hash
+// size works. The offset reaches 15, so digests must be at least 19 bytes,
+// which rules out MD5 in hashFor.
+func (p *otpParams) truncate(counter uint64, out []byte) {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], counter)
+	p.mac.Reset()
+	p.mac.Write(msg[:])
+	sum := p.mac.Sum(p.sum[:0])
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:]) & 0x7fffffff
+	for i := len(out) - 1; i >= 0; i-- {
+		out[i] = '0' + byte(value%10)
+		value /= 10
+	}
+}
+
+func hashFor(name string) (func() hash.Hash, error) {
+	switch strings.ToUpper(name) {
+	case "SHA1":
+		return sha1.New, nil
+	case "SHA256":
+		return sha256.New, nil
+	case "SHA512":
+		return sha512.New, nil
+	}
+	return nil, ErrUnsupportedAlgorithm
+}
@@ -0,0 +1,283 @@
+package g2fa
+
+import (
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base32"
+	"errors"
+	"math"
+	"testing"
+	"time"
+)
+
+// RFC test secrets: the ASCII seeds of RFC 4226 appendix D and RFC 6238
+// appendix B, base32 encoded.
+var (
+	rfcSecretSHA1   = base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+	rfcSecretSHA256 = base32.StdEncoding.EncodeToString([]byte("12345678901234567890123456789012"))
+	rfcSecretSHA512 = base32.StdEncoding.EncodeToString([]byte("1234567890123456789012345678901234567890123456789012345678901234"))
+)
+
+// RFC 4226 appendix D.
+var rfc4226Codes = []string{
+	"755224", "287082", "359152", "969429", "338314",
+	"254676", "287922", "162583", "399871", "520489",
+}
+
+// RFC 6238 appendix B.
+var rfc6238Vectors = []struct {
+	unix   int64
+	sha1   string
+	sha256 string
+	sha512 string
+}{
+	{59, "94287082", "46119246", "90693936"},
+	{1111111109, "07081804", "68084774", "25091201"},
+	{1111111111, "14050471", "67062674", "99943326"},
+	{1234567890, "89005924", "91819424", "93441116"},
+	{2000000000, "69279037", "90698825", "38618901"},
+	{20000000000, "65353130", "77737706", "47863826"},
+}
+
+func TestHOTPVectors(t *testing.T) {
+	for counter, want := range rfc4226Codes {
+		got, err := GenerateHOTP(rfcSecretSHA1, uint64(counter))
+		if err != nil || got != want {
+			t.Errorf("GenerateHOTP(%d) = %q, %v, want %q", counter, got, err, want)
+		}
+		matched, ok, err := ValidateHOTP(rfcSecretSHA1, want, uint64(counter))
+		if err != nil || !ok || matched != uint64(counter) {
+			t.Errorf("ValidateHOTP(%q, %d) = %d, %v, %v", want, counter, matched, ok, err)
+		}
+	}
+}
+
+func TestTOTPVectors(t *testing.T) {
+	for _, v := range rfc6238Vectors {
+		at := time.Unix(v.unix, 0)
+		for _, c := range []struct {
+			algorithm, secret, code string
+		}{
+			{"SHA1", rfcSecretSHA1, v.sha1},
+			{"SHA256", rfcSecretSHA256, v.sha256},
+			{"SHA512", rfcSecretSHA512, v.sha512},
+		} {
+			opts := []Option{WithAlgorithm(c.algorithm), WithDigits(8)}
+			got, err := GenerateTOTP(c.secret, at, opts...)
+			if err != nil || got != c.code {
+				t.Errorf("GenerateTOTP(%s, %d) = %q, %v, want %q", c.algorithm, v.unix, got, err, c.code)
+			}
+			step, ok, err := ValidateTOTP(c.secret, c.code, at, append(opts, WithWindow(0))...)
+			if err != nil || !ok || step != uint64(v.unix/30) {
+				t.Errorf("ValidateTOTP(%s, %q, %d) = %d, %v, %v", c.algorithm, c.code, v.unix, step, ok, err)
+			}
+		}
+	}
+}
+
+func TestValidateTOTPWindow(t *testing.T) {
+	code := "94287082" // step 1
+	tests := []struct {
+		unix   int64
+		window int
+		ok     bool
+	}{
+		{59, 0, true},
+		{89, 0, false},
+		{89, 1, true},
+		{0, 1, true}, // step 0: the window must not reach below step 0
+		{0, 0, false},
+		{119, 1, false},
+		{119, 2, true},
+	}
+	for _, tt := range tests {
+		step, ok, err := ValidateTOTP(rfcSecretSHA1, code, time.Unix(tt.unix, 0), WithDigits(8), WithWindow(tt.window))
+		if err != nil || ok != tt.ok || (ok && step != 1) {
+			t.Errorf("ValidateTOTP at %d window %d = %d, %v, %v, want ok = %v", tt.unix, tt.window, step, ok, err, tt.ok)
+		}
+	}
+
+	// At step 0 only steps 0 and up are candidates.
+	zero, _ := GenerateTOTP(rfcSecretSHA1, time.Unix(0, 0))
+	if step, ok, err := ValidateTOTP(rfcSecretSHA1, zero, time.Unix(0, 0), WithWindow(MaxWindow)); err != nil || !ok || step != 0 {
+		t.Errorf("ValidateTOTP at step 0 = %d, %v, %v", step, ok, err)
+	}
+}
+
+func TestValidateHOTPWindow(t *testing.T) {
+	matched, ok, err := ValidateHOTP(rfcSecretSHA1, rfc4226Codes[7], 3, WithWindow(5))
+	if err != nil || !ok || matched != 7 {
+		t.Errorf("ValidateHOTP look-ahead = %d, %v, %v, want 7", matched, ok, err)
+	}
+	if _, ok, _ := ValidateHOTP(rfcSecretSHA1, rfc4226Codes[7], 3, WithWindow(3)); ok {
+		t.Error("ValidateHOTP accepted a code beyond the window")
+	}
+	if _, ok, _ := ValidateHOTP(rfcSecretSHA1, rfc4226Codes[2], 3, WithWindow(5)); ok {
+		t.Error("ValidateHOTP accepted a code behind the counter")
+	}
+
+	// The window must stop at the largest counter instead of wrapping to 0.
+	if _, ok, err := ValidateHOTP(rfcSecretSHA1, rfc4226Codes[0], math.MaxUint64, WithWindow(2)); ok || err != nil {
+		t.Errorf("ValidateHOTP wrapped past the largest counter: %v, %v", ok, err)
+	}
+	last, _ := GenerateHOTP(rfcSecretSHA1, math.MaxUint64)
+	if matched, ok, err := ValidateHOTP(rfcSecretSHA1, last, math.MaxUint64-1, WithWindow(MaxWindow)); err != nil || !ok || matched != math.MaxUint64 {
+		t.Errorf("ValidateHOTP at the largest counter = %d, %v, %v", matched, ok, err)
+	}
+}
+
+func TestOptionErrors(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	tests := []struct {
+		name string
+		opts []Option
+		want error
+	}{
+		{"algorithm", []Option{WithAlgorithm("SHA3")}, ErrUnsupportedAlgorithm},
+		{"algorithm MD5", []Option{WithAlgorithm("MD5")}, ErrUnsupportedAlgorithm},
+		{"digits low", []Option{WithDigits(5)}, ErrInvalidDigits},
+		{"digits high", []Option{WithDigits(9)}, ErrInvalidDigits},
+		{"window negative", []Option{WithWindow(-1)}, ErrInvalidWindow},
+		{"window large", []Option{WithWindow(MaxWindow + 1)}, ErrInvalidWindow},
+		{"window max int", []Option{WithWindow(math.MaxInt)}, ErrInvalidWindow},
+		{"period zero", []Option{WithPeriod(0)}, ErrInvalidPeriod},
+		{"period fraction", []Option{WithPeriod(1500 * time.Millisecond)}, ErrInvalidPeriod},
+		{"period negative", []Option{WithPeriod(-30 * time.Second)}, ErrInvalidPeriod},
+	}
+	for _, tt := range tests {
+		if _, _, err := ValidateTOTP(rfcSecretSHA1, "123456", now, tt.opts...); err != tt.want {
+			t.Errorf("%s: ValidateTOTP error = %v, want %v", tt.name, err, tt.want)
+		}
+	}
+	// MD5 used to panic for digests truncated at offsets 13 to 15.
+	for counter := uint64(0); counter < 64; counter++ {
+		if _, err := GenerateHOTP(rfcSecretSHA1, counter, WithAlgorithm("md5")); err != ErrUnsupportedAlgorithm {
+			t.Fatalf("GenerateHOTP with MD5 error = %v, want ErrUnsupportedAlgorithm", err)
+		}
+	}
+	if _, err := GenerateTOTP(rfcSecretSHA1, time.Unix(-1, 0)); err != ErrInvalidTime {
+		t.Errorf("GenerateTOTP before epoch error = %v, want ErrInvalidTime", err)
+	}
+	if _, _, err := ValidateHOTP("not base32!", "123456", 0); err == nil {
+		t.Error("ValidateHOTP accepted an invalid secret")
+	}
+}
+
+func TestValidateNormalizesCode(t *testing.T) {
+	if _, ok, err := ValidateHOTP(rfcSecretSHA1, " ٧٥٥-٢٢٤ ", 0); err != nil || !ok {
+		t.Errorf("ValidateHOTP with Arabic-Indic digits = %v, %v", ok, err)
+	}
+}
+
+func TestValidateCodeFormat(t *testing.T) {
+	secret := "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+	tests := []struct {
+		code string
+		want error
+	}{
+		{"12345", &ErrBadLength{Got: 5, Want: 6}},
+		{"1234567", &ErrBadLength{Got: 7, Want: 6}},
+		{"12a456", &ErrNonDigit{Pos: 2}},
+		{"١٢x", &ErrNonDigit{Pos: 2}},
+	}
+	for _, tt := range tests {
+		_, ok, err := ValidateTOTP(secret, tt.code, time.Unix(59, 0))
+		if ok || err == nil || err.Error() != tt.want.Error() {
+			t.Errorf("ValidateTOTP(%q) = %v, %v, want %v", tt.code, ok, err, tt.want)
+		}
+		if !errors.Is(err, ErrInvalidCode) {
+			t.Errorf("ValidateTOTP(%q) error %v does not wrap ErrInvalidCode", tt.code, err)
+		}
+	}
+
+	var bad *ErrBadLength
+	if _, _, err := ValidateHOTP(secret, "12345", 0); !errors.As(err, &bad) || bad.Got != 5 || bad.Want != 6 {
+		t.Errorf("ValidateHOTP short code error = %v, want *ErrBadLength{5, 6}", err)
+	}
+}
+
+// The validators reuse one keyed HMAC across window candidates. Compare
+// BenchmarkHMACPerStep and BenchmarkHMACReset to see what that saves over
+// building a fresh HMAC for every step.
+
+func BenchmarkValidateTOTP(b *testing.B) {
+	at := time.Unix(1234567890, 0)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		ValidateTOTP(rfcSecretSHA1, "000000", at, WithWindow(10))
+	}
+}
+
+func BenchmarkHMACPerStep(b *testing.B) {
+	key := []byte("12345678901234567890")
+	var msg [8]byte
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		mac := hmac.New(sha1.New, key)
+		mac.Write(msg[:])
+		mac.Sum(nil)
+	}
+}
+
+func BenchmarkHMACReset(b *testing.B) {
+	mac := hmac.New(sha1.New, []byte("12345678901234567890"))
+	var msg [8]byte
+	var sum [sha1.Size]byte
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		mac.Reset()
+		mac.Write(msg[:])
+		mac.Sum(sum[:0])
+	}
+}
+
+func TestGenerateTOTPOptions(t *testing.T) {
+	at := time.Unix(1111111109, 0)
+	tests := []struct {
+		opts []Option
+		want string
+	}{
+		{nil, "081804"},
+		{[]Option{WithDigits(7)}, "7081804"},
+		{[]Option{WithDigits(8)}, "07081804"},
+		{[]Option{WithPeriod(60 * time.Second), WithDigits(8)}, "19360094"},
+	}
+	for _, tt := range tests {
+		got, err := GenerateTOTP(rfcSecretSHA1, at, tt.opts...)
+		if err != nil || got != tt.want {
+			t.Errorf("GenerateTOTP = %q, %v, want %q", got, err, tt.want)
+		}
+		if _, ok, err := ValidateTOTP(rfcSecretSHA1, got, at, tt.opts...); err != nil || !ok {
+			t.Errorf("ValidateTOTP(%q) = %v, %v", got, ok, err)
+		}
+	}
+	if _, err := GenerateHOTP(rfcSecretSHA1, 0, WithDigits(10)); err != ErrInvalidDigits {
+		t.Errorf("GenerateHOTP with 10 digits error = %v, want ErrInvalidDigits", err)
+	}
+	if _, err := GenerateTOTP(rfcSecretSHA1, at, WithPeriod(time.Millisecond)); err != ErrInvalidPeriod {
+		t.Errorf("GenerateTOTP with 1ms period error = %v, want ErrInvalidPeriod", err)
+	}
+}
+
+func TestTimeRemaining(t *testing.T) {
+	tests := []struct {
+		at   time.Time
+		opts []Option
+		want time.Duration
+	}{
+		{time.Unix(60, 0), nil, 30 * time.Second},
+		{time.Unix(61, 500), nil, 29*time.Second - 500},
+		{time.Unix(89, 999999999), nil, time.Nanosecond},
+		{time.Unix(61, 0), []Option{WithPeriod(time.Minute)}, 59 * time.Second},
+		{time.Unix(61, 0).In(time.FixedZone("UTC+3", 3*3600)), nil, 29 * time.Second},
+	}
+	for _, tt := range tests {
+		got, err := TimeRemaining(tt.at, tt.opts...)
+		if err != nil || got != tt.want {
+			t.Errorf("TimeRemaining(%v) = %v, %v, want %v", tt.at, got, err, tt.want)
+		}
+	}
+	if _, err := TimeRemaining(time.Unix(0, 0), WithPeriod(0)); err != ErrInvalidPeriod {
+		t.Errorf("TimeRemaining with zero period error = %v, want ErrInvalidPeriod", err)
+	}
+}
@@ -0,0 +1,10 @@
+module github.com/ghoroubi/g2fa/qr
+
+go 1.21
+
+require (
+	github.com/ghoroubi/g2fa v0.0.0-00010101000000-000000000000
+	github.com/skip2/go-qrcode v0.0.0-20200617195104-da1b6568686e
+)
+
+replace github.com/ghoroubi/g2fa => ../
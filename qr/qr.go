@@ -0,0 +1,95 @@
+// Package qr renders g2fa keys as QR codes for authenticator apps to scan,
+// as PNG, SVG or terminal text.
+//
+// It is a separate module so that the QR encoder dependency stays out of
+// the core g2fa package.
+package qr
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/ghoroubi/g2fa"
+	qrcode "github.com/skip2/go-qrcode"
+)
+
+// PNG renders the key's URI, as returned by Key.URI, as a size by size
+// pixel PNG QR code for authenticator apps to scan. A negative size sets
+// the width of each QR module in pixels instead, as in go-qrcode.
+func PNG(k *g2fa.Key, size int) ([]byte, error) {
+	uri, err := k.URI()
+	if err != nil {
+		return nil, err
+	}
+	return qrcode.Encode(uri, qrcode.Medium, size)
+}
+
+// Terminal renders the key's URI as a QR code made of UTF-8 block
+// characters, two modules per character cell, for enrollment over SSH. The
+// light modules are drawn, so the code scans on terminals with a dark
+// background.
+func Terminal(k *g2fa.Key) (string, error) {
+	uri, err := k.URI()
+	if err != nil {
+		return "", err
+	}
+	q, err := qrcode.New(uri, qrcode.Medium)
+	if err != nil {
+		return "", err
+	}
+	bitmap := q.Bitmap()
+
+	var b strings.Builder
+	for y := 0; y < len(bitmap); y += 2 {
+		for x := range bitmap[y] {
+			top := !bitmap[y][x]
+			bottom := y+1 >= len(bitmap) || !bitmap[y+1][x]
+			switch {
+			case top && bottom:
+				b.WriteRune('█')
+			case top:
+				b.WriteRune('▀')
+			case bottom:
+				b.WriteRune('▄')
+			default:
+				b.WriteByte(' ')
+			}
+		}
+		b.WriteByte('\n')
+	}
+	return b.String(), nil
+}
+
+// SVG renders the key's URI as an SVG QR code. The image has a
+// viewBox but no size, so it scales to its container and can be inlined in
+// HTML.
+func SVG(k *g2fa.Key) (string, error) {
+	uri, err := k.URI()
+	if err != nil {
+		return "", err
+	}
+	q, err := qrcode.New(uri, qrcode.Medium)
+	if err != nil {
+		return "", err
+	}
+	bitmap := q.Bitmap()
+
+	var b strings.Builder
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" shape-rendering="crispEdges">`, len(bitmap), len(bitmap))
+	b.WriteString(`<rect width="100%" height="100%" fill="#fff"/><path fill="#000" d="`)
+	// One subpath per horizontal run of dark modules.
+	for y, row := range bitmap {
+		for x := 0; x < len(row); x++ {
+			if !row[x] {
+				continue
+			}
+			start := x
+			for x < len(row) && row[x] {
+				x++
+			}
+			fmt.Fprintf(&b, "M%d %dh%dv1h-%dz", start, y, x-start, x-start)
+		}
+	}
+	b.WriteString(`"/></svg>`)
+	return b.String(), nil
+}
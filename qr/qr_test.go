@@ -0,0 +1,98 @@
+package qr
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"image/png"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/ghoroubi/g2fa"
+)
+
+var qrKey = &g2fa.Key{Type: g2fa.TypeTOTP, Label: "alice@example.com", Issuer: "Example", Secret: "JBSWY3DPEHPK3PXP"}
+
+func TestPNG(t *testing.T) {
+	data, err := PNG(qrKey, 256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b := img.Bounds(); b.Dx() != 256 || b.Dy() != 256 {
+		t.Errorf("image is %dx%d, want 256x256", b.Dx(), b.Dy())
+	}
+
+	bad := &g2fa.Key{Type: g2fa.TypeTOTP, Label: "alice", Issuer: "A:B", Secret: "AAAA"}
+	if _, err := PNG(bad, 256); err != g2fa.ErrInvalidIssuer {
+		t.Errorf("PNG with colon issuer error = %v, want ErrInvalidIssuer", err)
+	}
+}
+
+func TestTerminal(t *testing.T) {
+	out, err := Terminal(qrKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSuffix(out, "\n"), "\n")
+	width := utf8.RuneCountInString(lines[0])
+	// A version 1 to 40 symbol with its 4 module quiet zone on each side.
+	if width < 21+8 || width > 177+8 || len(lines) != (width+1)/2 {
+		t.Fatalf("got %d lines of %d cells, want a square symbol", len(lines), width)
+	}
+	for i, line := range lines {
+		if n := utf8.RuneCountInString(line); n != width {
+			t.Errorf("line %d has %d cells, want %d", i, n, width)
+		}
+		if strings.Trim(line, " █▀▄") != "" {
+			t.Errorf("line %d has characters other than blocks: %q", i, line)
+		}
+	}
+	// The quiet zone is light, so the first rows are full blocks.
+	if lines[0] != strings.Repeat("█", width) {
+		t.Errorf("first line = %q, want full blocks", lines[0])
+	}
+
+	bad := &g2fa.Key{Type: g2fa.TypeTOTP, Label: "alice", Issuer: "A:B", Secret: "AAAA"}
+	if _, err := Terminal(bad); err != g2fa.ErrInvalidIssuer {
+		t.Errorf("Terminal with colon issuer error = %v, want ErrInvalidIssuer", err)
+	}
+}
+
+func TestSVG(t *testing.T) {
+	out, err := SVG(qrKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var svg struct {
+		XMLName xml.Name `xml:"svg"`
+		ViewBox string   `xml:"viewBox,attr"`
+		Width   string   `xml:"width,attr"`
+		Path    struct {
+			D string `xml:"d,attr"`
+		} `xml:"path"`
+	}
+	if err := xml.Unmarshal([]byte(out), &svg); err != nil {
+		t.Fatalf("SVG is not valid XML: %v", err)
+	}
+	var w, h int
+	if _, err := fmt.Sscanf(svg.ViewBox, "0 0 %d %d", &w, &h); err != nil || w != h || w < 21+8 {
+		t.Errorf("viewBox = %q, want a square symbol", svg.ViewBox)
+	}
+	if svg.Width != "" {
+		t.Errorf("width = %q, want none so the image scales", svg.Width)
+	}
+	// The quiet zone leaves the first 4 rows and columns light.
+	if !strings.HasPrefix(svg.Path.D, "M4 4h7v1h-7z") {
+		t.Errorf("path starts %.20q, want the top of the first finder pattern", svg.Path.D)
+	}
+
+	bad := &g2fa.Key{Type: g2fa.TypeTOTP, Label: "alice", Issuer: "A:B", Secret: "AAAA"}
+	if _, err := SVG(bad); err != g2fa.ErrInvalidIssuer {
+		t.Errorf("SVG with colon issuer error = %v, want ErrInvalidIssuer", err)
+	}
+}
@@ -0,0 +1,106 @@
+package g2fa
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/csv"
+	"errors"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const (
+	// ScratchCodeDigits is the length of scratch codes produced by
+	// GenerateScratchCodes.
+	ScratchCodeDigits = 8
+	// MaxScratchCodes is the largest number of codes GenerateScratchCodes
+	// returns in one call.
+	MaxScratchCodes = 1000
+)
+
+const (
+	minScratchCode = 10000000
+	maxScratchCode = 99999999
+)
+
+// ErrScratchCodeCount is returned by GenerateScratchCodes when n is not
+// between 1 and MaxScratchCodes.
+var ErrScratchCodeCount = errors.New("g2fa: scratch code count must be between 1 and 1000")
+
+// GenerateScratchCodes returns n distinct random 8-digit scratch codes drawn
+// uniformly from crypto/rand. Codes never start with a zero, so they keep
+// their length when handled as integers.
+func GenerateScratchCodes(n int) ([]int, error) {
+	if n <= 0 || n > MaxScratchCodes {
+		return nil, ErrScratchCodeCount
+	}
+	span := big.NewInt(maxScratchCode - minScratchCode + 1)
+	codes := make([]int, 0, n)
+	seen := make(map[int]bool, n)
+	for len(codes) < n {
+		v, err := rand.Int(rand.Reader, span)
+		if err != nil {
+			return nil, err
+		}
+		code := minScratchCode + int(v.Int64())
+		if seen[code] {
+			continue
+		}
+		seen[code] = true
+		codes = append(codes, code)
+	}
+	return codes, nil
+}
+
+// ScratchCodesText renders scratch codes as plain text, one per line, for a
+// "download your codes" file.
+func ScratchCodesText(codes []int) string {
+	var b strings.Builder
+	for _, code := range codes {
+		b.WriteString(strconv.Itoa(code))
+		b.WriteByte('\n')
+	}
+	return b.String()
+}
+
+// ScratchCodesCSV renders scratch codes as CSV with a single "code" column.
+func ScratchCodesCSV(codes []int) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write([]string{"code"}); err != nil {
+		return nil, err
+	}
+	for _, code := range codes {
+		if err := w.Write([]string{strconv.Itoa(code)}); err != nil {
+			return nil, err
+		}
+	}
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+// ScratchCodesHTML renders scratch codes as an HTML list to embed in a page.
+// The list carries a data-copy attribute with all codes and each item one
+// with its own code, for copy-to-clipboard scripts to pick up. Styling is
+// left to the page through the g2fa-scratch-codes class.
+func ScratchCodesHTML(codes []int) string {
+	all := make([]string, len(codes))
+	for i, code := range codes {
+		all[i] = strconv.Itoa(code)
+	}
+
+	var b strings.Builder
+	b.WriteString(`<ul class="g2fa-scratch-codes" data-copy="`)
+	b.WriteString(strings.Join(all, " "))
+	b.WriteString("\">\n")
+	for _, code := range all {
+		b.WriteString(`  <li><code data-copy="`)
+		b.WriteString(code)
+		b.WriteString(`">`)
+		b.WriteString(code)
+		b.WriteString("</code></li>\n")
+	}
+	b.WriteString("</ul>\n")
+	return b.String()
+}
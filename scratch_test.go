@@ -0,0 +1,61 @@
+package g2fa
+
+import "testing"
+
+func TestGenerateScratchCodes(t *testing.T) {
+	codes, err := GenerateScratchCodes(MaxScratchCodes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(codes) != MaxScratchCodes {
+		t.Fatalf("got %d codes, want %d", len(codes), MaxScratchCodes)
+	}
+	seen := make(map[int]bool)
+	for _, code := range codes {
+		if code < 10000000 || code > 99999999 {
+			t.Errorf("code %d is not 8 digits with a non-zero leading digit", code)
+		}
+		if seen[code] {
+			t.Errorf("code %d returned twice", code)
+		}
+		seen[code] = true
+	}
+}
+
+func TestGenerateScratchCodesCount(t *testing.T) {
+	for _, n := range []int{-1, 0, MaxScratchCodes + 1, 90000001} {
+		if _, err := GenerateScratchCodes(n); err != ErrScratchCodeCount {
+			t.Errorf("GenerateScratchCodes(%d) error = %v, want ErrScratchCodeCount", n, err)
+		}
+	}
+}
+
+func TestScratchCodesText(t *testing.T) {
+	if got, want := ScratchCodesText([]int{12345678, 87654321}), "12345678\n87654321\n"; got != want {
+		t.Errorf("ScratchCodesText = %q, want %q", got, want)
+	}
+	if got := ScratchCodesText(nil); got != "" {
+		t.Errorf("ScratchCodesText(nil) = %q, want empty", got)
+	}
+}
+
+func TestScratchCodesCSV(t *testing.T) {
+	got, err := ScratchCodesCSV([]int{12345678, 87654321})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "code\n12345678\n87654321\n"; string(got) != want {
+		t.Errorf("ScratchCodesCSV = %q, want %q", got, want)
+	}
+}
+
+func TestScratchCodesHTML(t *testing.T) {
+	want := `<ul class="g2fa-scratch-codes" data-copy="12345678 87654321">
+  <li><code data-copy="12345678">12345678</code></li>
+  <li><code data-copy="87654321">87654321</code></li>
+</ul>
+`
+	if got := ScratchCodesHTML([]int{12345678, 87654321}); got != want {
+		t.Errorf("ScratchCodesHTML =\n%s\nwant\n%s", got, want)
+	}
+}
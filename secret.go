@@ -0,0 +1,97 @@
+package g2fa
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"errors"
+	"strings"
+)
+
+// MinSecretLength is the smallest secret size, in bytes, accepted by
+// GenerateSecret. RFC 4226 requires shared secrets of at least 128 bits.
+const MinSecretLength = 16
+
+// ErrSecretLength is returned by GenerateSecret for lengths below
+// MinSecretLength.
+var ErrSecretLength = errors.New("g2fa: secret length must be at least 16 bytes")
+
+// GenerateSecret returns a new random secret of length bytes, read from
+// crypto/rand and encoded as padded base32 (RFC 4648), the form authenticator
+// apps expect. A length of 20 gives the 160-bit secret recommended by RFC 4226.
+func GenerateSecret(length int) (string, error) {
+	return GenerateSecretWith(length, StdSecretEncoding)
+}
+
+// GenerateSecretWith is like GenerateSecret but encodes the secret with enc,
+// for systems that expect unpadded or Crockford base32. A nil enc means
+// StdSecretEncoding.
+func GenerateSecretWith(length int, enc *SecretEncoding) (string, error) {
+	if length < MinSecretLength {
+		return "", ErrSecretLength
+	}
+	if enc == nil {
+		enc = StdSecretEncoding
+	}
+	buf := make([]byte, length)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	secret := enc.EncodeToString(buf)
+	wipe(buf)
+	return secret, nil
+}
+
+// crockfordAlphabet is the symbol set of Crockford's base32, which leaves
+// out I, L, O and U.
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// SecretEncoding is a base32 variant used to read and write secrets.
+type SecretEncoding struct {
+	enc       *base32.Encoding // unpadded
+	padded    bool
+	crockford bool
+}
+
+// Base32 variants for secrets. All of them decode case-insensitively,
+// ignore spaces and accept input with or without '=' padding; they differ
+// in alphabet and in whether EncodeToString pads.
+var (
+	// StdSecretEncoding is RFC 4648 base32 with padding, the form
+	// GenerateSecret returns. It is the default.
+	StdSecretEncoding = &SecretEncoding{enc: base32.StdEncoding.WithPadding(base32.NoPadding), padded: true}
+	// RawSecretEncoding is RFC 4648 base32 without padding, as commonly
+	// written in otpauth URIs.
+	RawSecretEncoding = &SecretEncoding{enc: base32.StdEncoding.WithPadding(base32.NoPadding)}
+	// CrockfordSecretEncoding is Crockford's base32, unpadded. Decoding also
+	// ignores hyphens and reads I and L as 1 and O as 0.
+	CrockfordSecretEncoding = &SecretEncoding{enc: base32.NewEncoding(crockfordAlphabet).WithPadding(base32.NoPadding), crockford: true}
+)
+
+// crockfordReplacer applies the decoding rules of Crockford's base32 to
+// uppercased input.
+var crockfordReplacer = strings.NewReplacer("-", "", "I", "1", "L", "1", "O", "0")
+
+// EncodeToString encodes a raw secret.
+func (e *SecretEncoding) EncodeToString(secret []byte) string {
+	s := e.enc.EncodeToString(secret)
+	if e.padded {
+		s += strings.Repeat("=", (8-len(s)%8)%8)
+	}
+	return s
+}
+
+// DecodeString decodes an encoded secret.
+func (e *SecretEncoding) DecodeString(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	if e.crockford {
+		secret = crockfordReplacer.Replace(secret)
+	}
+	secret = strings.TrimRight(secret, "=")
+	return e.enc.DecodeString(secret)
+}
+
+// decodeSecret decodes a base32 secret as found in keys and otpauth URIs,
+// tolerating lowercase letters, spaces and missing padding.
+func decodeSecret(secret string) ([]byte, error) {
+	return StdSecretEncoding.DecodeString(secret)
+}
@@ -0,0 +1,160 @@
+package g2fa
+
+import (
+	"bytes"
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerateSecret(t *testing.T) {
+	tests := []struct {
+		length  int
+		encoded int
+	}{
+		{16, 32},
+		{20, 32},
+		{32, 56},
+	}
+	for _, tt := range tests {
+		secret, err := GenerateSecret(tt.length)
+		if err != nil {
+			t.Fatalf("GenerateSecret(%d): %v", tt.length, err)
+		}
+		if len(secret) != tt.encoded {
+			t.Errorf("GenerateSecret(%d) = %q, want %d characters", tt.length, secret, tt.encoded)
+		}
+		raw, err := base32.StdEncoding.DecodeString(secret)
+		if err != nil {
+			t.Errorf("GenerateSecret(%d) = %q is not padded base32: %v", tt.length, secret, err)
+		}
+		if len(raw) != tt.length {
+			t.Errorf("GenerateSecret(%d) decodes to %d bytes", tt.length, len(raw))
+		}
+	}
+
+	a, _ := GenerateSecret(20)
+	b, _ := GenerateSecret(20)
+	if a == b {
+		t.Error("GenerateSecret returned the same secret twice")
+	}
+}
+
+func TestGenerateSecretLength(t *testing.T) {
+	for _, length := range []int{-1, 0, 10, MinSecretLength - 1} {
+		if _, err := GenerateSecret(length); err != ErrSecretLength {
+			t.Errorf("GenerateSecret(%d) error = %v, want ErrSecretLength", length, err)
+		}
+	}
+}
+
+func TestDecodeSecret(t *testing.T) {
+	want := []byte("12345678901234567890")
+	for _, in := range []string{
+		"GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ",
+		"gezdgnbvgy3tqojqgezdgnbvgy3tqojq",
+		"GEZD GNBV GY3T QOJQ GEZD GNBV GY3T QOJQ",
+	} {
+		got, err := decodeSecret(in)
+		if err != nil || !bytes.Equal(got, want) {
+			t.Errorf("decodeSecret(%q) = %q, %v", in, got, err)
+		}
+	}
+	padded := base32.StdEncoding.EncodeToString([]byte("123456789012"))
+	if !strings.HasSuffix(padded, "=") {
+		t.Fatalf("test secret %q should need padding", padded)
+	}
+	for _, in := range []string{padded, strings.TrimRight(padded, "=")} {
+		if got, err := decodeSecret(in); err != nil || string(got) != "123456789012" {
+			t.Errorf("decodeSecret(%q) = %q, %v", in, got, err)
+		}
+	}
+	if _, err := decodeSecret("not base32!"); err == nil {
+		t.Error("decodeSecret accepted invalid input")
+	}
+}
+
+func TestSecretEncoding(t *testing.T) {
+	raw := []byte("Hello!")
+	tests := []struct {
+		enc  *SecretEncoding
+		want string
+		in   []string
+	}{
+		{StdSecretEncoding, "JBSWY3DPEE======", []string{"JBSWY3DPEE", "jbsw y3dp ee=="}},
+		{RawSecretEncoding, "JBSWY3DPEE", []string{"JBSWY3DPEE======", "jbswy3dpee"}},
+		{CrockfordSecretEncoding, "91JPRV3F44", []string{"91jp-rv3f-44", "9IJPRV3F44", "9lJPRV3F44"}},
+	}
+	for _, tt := range tests {
+		if got := tt.enc.EncodeToString(raw); got != tt.want {
+			t.Errorf("EncodeToString = %q, want %q", got, tt.want)
+		}
+		for _, in := range append(tt.in, tt.want) {
+			got, err := tt.enc.DecodeString(in)
+			if err != nil || !bytes.Equal(got, raw) {
+				t.Errorf("DecodeString(%q) = %q, %v, want %q", in, got, err, raw)
+			}
+		}
+	}
+
+	// O reads as 0 in Crockford's alphabet.
+	zero, err := CrockfordSecretEncoding.DecodeString("OOOOOOOO")
+	if err != nil || !bytes.Equal(zero, make([]byte, 5)) {
+		t.Errorf("DecodeString(OOOOOOOO) = %x, %v, want zeros", zero, err)
+	}
+	if _, err := CrockfordSecretEncoding.DecodeString("UUUUUUUU"); err == nil {
+		t.Error("Crockford decoding accepted U")
+	}
+	if _, err := StdSecretEncoding.DecodeString("JBSWY3DPE1"); err == nil {
+		t.Error("standard decoding accepted 1")
+	}
+}
+
+func TestWithSecretEncoding(t *testing.T) {
+	at := time.Unix(59, 0)
+	want, err := GenerateTOTP("GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ", at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := GenerateTOTP("64S3-6D1N-6RVK-GE9G-64S3-6D1N-6RVK-GE9G", at, WithSecretEncoding(CrockfordSecretEncoding))
+	if err != nil || got != want {
+		t.Errorf("GenerateTOTP with Crockford secret = %q, %v, want %q", got, err, want)
+	}
+	if _, err := GenerateTOTP("64S36D1N6RVKGE9G", at); err == nil {
+		t.Error("GenerateTOTP decoded a Crockford secret with the default encoding")
+	}
+}
+
+func TestGenerateSecretWith(t *testing.T) {
+	tests := []struct {
+		enc     *SecretEncoding
+		encoded int
+		chars   string
+	}{
+		{nil, 32, "ABCDEFGHIJKLMNOPQRSTUVWXYZ234567="},
+		{StdSecretEncoding, 32, "ABCDEFGHIJKLMNOPQRSTUVWXYZ234567="},
+		{RawSecretEncoding, 26, "ABCDEFGHIJKLMNOPQRSTUVWXYZ234567"},
+		{CrockfordSecretEncoding, 26, crockfordAlphabet},
+	}
+	for _, tt := range tests {
+		// 16 bytes need padding in RFC 4648 base32.
+		secret, err := GenerateSecretWith(16, tt.enc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(secret) != tt.encoded || strings.Trim(secret, tt.chars) != "" {
+			t.Errorf("GenerateSecretWith(16, %p) = %q, want %d characters from %s", tt.enc, secret, tt.encoded, tt.chars)
+		}
+		enc := tt.enc
+		if enc == nil {
+			enc = StdSecretEncoding
+		}
+		if raw, err := enc.DecodeString(secret); err != nil || len(raw) != 16 {
+			t.Errorf("decoding %q = %d bytes, %v", secret, len(raw), err)
+		}
+	}
+	if _, err := GenerateSecretWith(MinSecretLength-1, RawSecretEncoding); err != ErrSecretLength {
+		t.Errorf("GenerateSecretWith(short) error = %v, want ErrSecretLength", err)
+	}
+}
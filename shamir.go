@@ -0,0 +1,130 @@
+package g2fa
+
+import (
+	"crypto/rand"
+	"errors"
+)
+
+var (
+	// ErrInvalidShareParams is returned by SplitSecret when n and k do not
+	// describe a valid threshold scheme.
+	ErrInvalidShareParams = errors.New("g2fa: shares require 2 <= k <= n <= 255")
+	// ErrInvalidShares is returned by CombineSecret when the shares are
+	// malformed, of different lengths or duplicated.
+	ErrInvalidShares = errors.New("g2fa: invalid secret shares")
+)
+
+// SplitSecret splits secret into n Shamir shares, any k of which are enough
+// to rebuild it with CombineSecret. Fewer than k shares reveal nothing about
+// the secret. Each share is one byte longer than the secret: the first byte
+// is the share's x coordinate, the rest its evaluation of the polynomial.
+func SplitSecret(secret []byte, n, k int) ([][]byte, error) {
+	if k < 2 || n < k || n > 255 {
+		return nil, ErrInvalidShareParams
+	}
+	if len(secret) == 0 {
+		return nil, ErrInvalidShares
+	}
+
+	shares := make([][]byte, n)
+	for i := range shares {
+		shares[i] = make([]byte, len(secret)+1)
+		shares[i][0] = byte(i + 1)
+	}
+
+	coeffs := make([]byte, k)
+	for j, s := range secret {
+		coeffs[0] = s
+		if _, err := rand.Read(coeffs[1:]); err != nil {
+			return nil, err
+		}
+		for _, share := range shares {
+			share[j+1] = gfEval(coeffs, share[0])
+		}
+	}
+	wipe(coeffs)
+	return shares, nil
+}
+
+// CombineSecret rebuilds a secret from shares produced by SplitSecret. It
+// cannot tell whether enough shares were supplied: with fewer than the
+// threshold it returns garbage rather than an error.
+func CombineSecret(shares [][]byte) ([]byte, error) {
+	if len(shares) < 2 {
+		return nil, ErrInvalidShares
+	}
+	size := len(shares[0])
+	if size < 2 {
+		return nil, ErrInvalidShares
+	}
+	seen := make(map[byte]bool, len(shares))
+	for _, share := range shares {
+		if len(share) != size || share[0] == 0 || seen[share[0]] {
+			return nil, ErrInvalidShares
+		}
+		seen[share[0]] = true
+	}
+
+	secret := make([]byte, size-1)
+	for i, si := range shares {
+		// Lagrange basis polynomial for share i evaluated at x = 0.
+		basis := byte(1)
+		for j, sj := range shares {
+			if i != j {
+				basis = gfMul(basis, gfDiv(sj[0], sj[0]^si[0]))
+			}
+		}
+		for b := range secret {
+			secret[b] ^= gfMul(basis, si[b+1])
+		}
+	}
+	return secret, nil
+}
+
+// GF(2^8) arithmetic over the AES polynomial x^8 + x^4 + x^3 + x + 1.
+var gfExp, gfLog = func() (exp [510]byte, log [256]byte) {
+	x := byte(1)
+	for i := 0; i < 255; i++ {
+		exp[i] = x
+		exp[i+255] = x
+		log[x] = byte(i)
+		// multiply by the generator 3
+		hi := x & 0x80
+		x2 := x << 1
+		if hi != 0 {
+			x2 ^= 0x1b
+		}
+		x ^= x2
+	}
+	return
+}()
+
+func gfMul(a, b byte) byte {
+	if a == 0 || b == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+int(gfLog[b])]
+}
+
+func gfDiv(a, b byte) byte {
+	if a == 0 {
+		return 0
+	}
+	return gfExp[int(gfLog[a])+255-int(gfLog[b])]
+}
+
+// gfEval evaluates the polynomial with the given coefficients at x using
+// Horner's method.
+func gfEval(coeffs []byte, x byte) byte {
+	var y byte
+	for i := len(coeffs) - 1; i >= 0; i-- {
+		y = gfMul(y, x) ^ coeffs[i]
+	}
+	return y
+}
+
+func wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
@@ -0,0 +1,89 @@
+package g2fa
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSplitCombineSecret(t *testing.T) {
+	secret := []byte("12345678901234567890")
+	shares, err := SplitSecret(secret, 5, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shares) != 5 {
+		t.Fatalf("got %d shares, want 5", len(shares))
+	}
+	for _, share := range shares {
+		if len(share) != len(secret)+1 {
+			t.Fatalf("share length %d, want %d", len(share), len(secret)+1)
+		}
+	}
+
+	subsets := [][]int{{0, 1, 2}, {4, 2, 0}, {1, 3, 4}, {0, 1, 2, 3}, {0, 1, 2, 3, 4}}
+	for _, subset := range subsets {
+		var in [][]byte
+		for _, i := range subset {
+			in = append(in, shares[i])
+		}
+		got, err := CombineSecret(in)
+		if err != nil {
+			t.Fatalf("CombineSecret(%v): %v", subset, err)
+		}
+		if !bytes.Equal(got, secret) {
+			t.Errorf("CombineSecret(%v) = %q, want %q", subset, got, secret)
+		}
+	}
+
+	got, err := CombineSecret(shares[:2])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Equal(got, secret) {
+		t.Error("CombineSecret recovered the secret from fewer than k shares")
+	}
+}
+
+func TestSplitSecretParams(t *testing.T) {
+	for _, p := range [][2]int{{1, 1}, {2, 1}, {2, 3}, {256, 3}} {
+		if _, err := SplitSecret([]byte("x"), p[0], p[1]); err != ErrInvalidShareParams {
+			t.Errorf("SplitSecret(n=%d, k=%d) error = %v, want ErrInvalidShareParams", p[0], p[1], err)
+		}
+	}
+	if _, err := SplitSecret(nil, 3, 2); err != ErrInvalidShares {
+		t.Errorf("SplitSecret(empty) error = %v, want ErrInvalidShares", err)
+	}
+	if _, err := SplitSecret([]byte("x"), 255, 255); err != nil {
+		t.Errorf("SplitSecret(n=255, k=255) error = %v", err)
+	}
+}
+
+func TestCombineSecretInvalid(t *testing.T) {
+	shares, err := SplitSecret([]byte("secret"), 3, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := map[string][][]byte{
+		"single":    {shares[0]},
+		"duplicate": {shares[0], shares[0]},
+		"length":    {shares[0], shares[1][:3]},
+		"zero x":    {shares[0], append([]byte{0}, shares[1][1:]...)},
+	}
+	for name, in := range tests {
+		if _, err := CombineSecret(in); err != ErrInvalidShares {
+			t.Errorf("%s: error = %v, want ErrInvalidShares", name, err)
+		}
+	}
+}
+
+func TestGF256(t *testing.T) {
+	for a := 1; a < 256; a++ {
+		if got := gfMul(gfDiv(1, byte(a)), byte(a)); got != 1 {
+			t.Fatalf("a * (1/a) = %d for a = %d", got, a)
+		}
+	}
+	// 0x53 * 0xca = 0x01 in the AES field.
+	if got := gfMul(0x53, 0xca); got != 1 {
+		t.Errorf("gfMul(0x53, 0xca) = %#x, want 0x01", got)
+	}
+}
@@ -0,0 +1,75 @@
+package g2fa
+
+import "time"
+
+// VectorMatrix lists the parameters GenerateVectors combines. Empty fields
+// fall back to a single default value, except Times, which defaults to the
+// test times of RFC 6238 Appendix B.
+type VectorMatrix struct {
+	Algorithms []string
+	Digits     []int
+	Periods    []time.Duration
+	Times      []time.Time
+}
+
+// Vector is one expected TOTP code. Its JSON form is meant to be shared with
+// implementations in other languages to check they agree with this package.
+type Vector struct {
+	Secret    string `json:"secret"` // base32, as given
+	Algorithm string `json:"algorithm"`
+	Digits    int    `json:"digits"`
+	Period    int    `json:"period"` // seconds
+	Time      int64  `json:"time"`   // Unix seconds
+	Step      uint64 `json:"step"`
+	Code      string `json:"code"`
+}
+
+// rfc6238Times are the test times of RFC 6238 Appendix B.
+var rfc6238Times = []int64{59, 1111111109, 1111111111, 1234567890, 2000000000, 20000000000}
+
+// GenerateVectors returns the TOTP code for secret at every combination of
+// the matrix parameters, in the order algorithms, digits, periods, times.
+// Any invalid parameter fails the whole call with the error GenerateTOTP
+// returns for it.
+func GenerateVectors(secret string, m VectorMatrix) ([]Vector, error) {
+	algorithms, digits, periods, times := m.Algorithms, m.Digits, m.Periods, m.Times
+	if len(algorithms) == 0 {
+		algorithms = []string{DefaultAlgorithm}
+	}
+	if len(digits) == 0 {
+		digits = []int{DefaultDigits}
+	}
+	if len(periods) == 0 {
+		periods = []time.Duration{DefaultPeriod}
+	}
+	if len(times) == 0 {
+		for _, unix := range rfc6238Times {
+			times = append(times, time.Unix(unix, 0))
+		}
+	}
+
+	vectors := make([]Vector, 0, len(algorithms)*len(digits)*len(periods)*len(times))
+	for _, algorithm := range algorithms {
+		for _, d := range digits {
+			for _, period := range periods {
+				for _, t := range times {
+					code, err := GenerateTOTP(secret, t, WithAlgorithm(algorithm), WithDigits(d), WithPeriod(period))
+					if err != nil {
+						return nil, err
+					}
+					step, _ := timeStep(t, period)
+					vectors = append(vectors, Vector{
+						Secret:    secret,
+						Algorithm: algorithm,
+						Digits:    d,
+						Period:    int(period / time.Second),
+						Time:      t.Unix(),
+						Step:      step,
+						Code:      code,
+					})
+				}
+			}
+		}
+	}
+	return vectors, nil
+}
@@ -0,0 +1,80 @@
+package g2fa
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestGenerateVectorsRFC6238(t *testing.T) {
+	vectors, err := GenerateVectors(rfcSecretSHA1, VectorMatrix{Digits: []int{8}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vectors) != len(rfc6238Vectors) {
+		t.Fatalf("got %d vectors, want %d", len(vectors), len(rfc6238Vectors))
+	}
+	for i, v := range vectors {
+		want := rfc6238Vectors[i]
+		if v.Time != want.unix || v.Code != want.sha1 || v.Step != uint64(want.unix/30) {
+			t.Errorf("vector %d = %+v, want time %d, step %d, code %s", i, v, want.unix, want.unix/30, want.sha1)
+		}
+		if v.Algorithm != "SHA1" || v.Digits != 8 || v.Period != 30 || v.Secret != rfcSecretSHA1 {
+			t.Errorf("vector %d parameters = %+v", i, v)
+		}
+	}
+}
+
+func TestGenerateVectorsMatrix(t *testing.T) {
+	m := VectorMatrix{
+		Algorithms: []string{"SHA1", "SHA256", "SHA512"},
+		Digits:     []int{6, 8},
+		Periods:    []time.Duration{30 * time.Second, 60 * time.Second},
+		Times:      []time.Time{time.Unix(59, 0), time.Unix(1234567890, 0)},
+	}
+	vectors, err := GenerateVectors(rfcSecretSHA1, m)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(vectors) != 3*2*2*2 {
+		t.Fatalf("got %d vectors, want 24", len(vectors))
+	}
+	for _, v := range vectors {
+		code, err := GenerateTOTP(v.Secret, time.Unix(v.Time, 0),
+			WithAlgorithm(v.Algorithm), WithDigits(v.Digits), WithPeriod(time.Duration(v.Period)*time.Second))
+		if err != nil || code != v.Code || len(v.Code) != v.Digits {
+			t.Errorf("vector %+v does not match GenerateTOTP = %q, %v", v, code, err)
+		}
+	}
+	if last := vectors[len(vectors)-1]; last.Algorithm != "SHA512" || last.Digits != 8 || last.Period != 60 || last.Time != 1234567890 {
+		t.Errorf("last vector = %+v, want SHA512, 8 digits, 60s, 1234567890", last)
+	}
+
+	out, err := json.Marshal(vectors[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, field := range []string{`"secret":`, `"algorithm":"SHA1"`, `"digits":6`, `"period":30`, `"time":59`, `"step":1`, `"code":`} {
+		if !strings.Contains(string(out), field) {
+			t.Errorf("JSON %s lacks %s", out, field)
+		}
+	}
+}
+
+func TestGenerateVectorsInvalid(t *testing.T) {
+	tests := []struct {
+		m    VectorMatrix
+		want error
+	}{
+		{VectorMatrix{Algorithms: []string{"SHA3"}}, ErrUnsupportedAlgorithm},
+		{VectorMatrix{Digits: []int{6, 9}}, ErrInvalidDigits},
+		{VectorMatrix{Periods: []time.Duration{1500 * time.Millisecond}}, ErrInvalidPeriod},
+		{VectorMatrix{Times: []time.Time{time.Unix(-1, 0)}}, ErrInvalidTime},
+	}
+	for _, tt := range tests {
+		if _, err := GenerateVectors(rfcSecretSHA1, tt.m); err != tt.want {
+			t.Errorf("GenerateVectors(%+v) error = %v, want %v", tt.m, err, tt.want)
+		}
+	}
+}